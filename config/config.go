@@ -1,6 +1,8 @@
 // Package config defines the provider configuration model and parsing helpers.
 package config
 
+import "strings"
+
 // ProviderConfig defines a single upstream provider to poll and filter.
 type ProviderConfig struct {
 	Name       string           `json:"name,omitempty" yaml:"name,omitempty"`
@@ -11,6 +13,43 @@ type ProviderConfig struct {
 	UDP        *UDPSection      `json:"udp,omitempty" yaml:"udp,omitempty"`
 	TLS        *TLSSection      `json:"tls,omitempty" yaml:"tls,omitempty"`
 	Tunnels    []TunnelConfig   `json:"tunnels,omitempty" yaml:"tunnels,omitempty"`
+
+	EntrypointMap      *EntrypointMapConfig      `json:"entrypointMap,omitempty" yaml:"entrypointMap,omitempty"`
+	EnforceMiddlewares *EnforceMiddlewaresConfig `json:"enforceMiddlewares,omitempty" yaml:"enforceMiddlewares,omitempty"`
+	Naming             *NamingConfig             `json:"naming,omitempty" yaml:"naming,omitempty"`
+	// PreserveProviders lists the provider suffixes (e.g. "internal", "file")
+	// kept on references when names are cleaned up. Defaults to ["internal"];
+	// set an empty list to strip every suffix.
+	PreserveProviders []string `json:"preserveProviders,omitempty" yaml:"preserveProviders,omitempty"`
+	// DanglingReferences is the policy applied to this provider's routers when
+	// they reference a service or middleware missing from the merged
+	// configuration. Defaults to DanglingWarn.
+	DanglingReferences string `json:"danglingReferences,omitempty" yaml:"danglingReferences,omitempty"`
+}
+
+// DefaultPreserveProviders is used when PreserveProviders is not set.
+var DefaultPreserveProviders = []string{"internal"}
+
+// PreservedProviders returns the provider suffixes kept on references.
+func (p *ProviderConfig) PreservedProviders() []string {
+	if p.PreserveProviders == nil {
+		return DefaultPreserveProviders
+	}
+	return p.PreserveProviders
+}
+
+// IsPreserved reports whether name carries one of the preserved provider suffixes.
+func IsPreserved(name string, preserved []string) bool {
+	i := strings.LastIndex(name, "@")
+	if i < 0 {
+		return false
+	}
+	for _, provider := range preserved {
+		if name[i+1:] == provider {
+			return true
+		}
+	}
+	return false
 }
 
 // ConnectionConfig configures how to connect to the upstream provider API.
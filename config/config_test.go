@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -281,3 +282,15 @@ func TestServicesConfig_ShouldApplyOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderConfig_PreservedProviders(t *testing.T) {
+	if got := (&ProviderConfig{}).PreservedProviders(); !reflect.DeepEqual(got, []string{"internal"}) {
+		t.Errorf("default=%v", got)
+	}
+	if got := (&ProviderConfig{PreserveProviders: []string{}}).PreservedProviders(); len(got) != 0 {
+		t.Errorf("empty=%v", got)
+	}
+	if !IsPreserved("api@internal", []string{"internal"}) || IsPreserved("api@docker", []string{"internal"}) || IsPreserved("internal", []string{"internal"}) {
+		t.Error("unexpected IsPreserved result")
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import "fmt"
+
+// Policies for router references that do not exist in the merged configuration.
+const (
+	// DanglingWarn logs dangling references and keeps the router as is.
+	DanglingWarn = "warn"
+	// DanglingDropRouter removes routers with a dangling service or middleware.
+	DanglingDropRouter = "dropRouter"
+	// DanglingDropMiddleware removes dangling middlewares from routers. Routers
+	// with a dangling service are kept and logged.
+	DanglingDropMiddleware = "dropMiddleware"
+)
+
+// ValidateDanglingPolicy checks a dangling reference policy.
+func ValidateDanglingPolicy(policy string) error {
+	switch policy {
+	case "", DanglingWarn, DanglingDropRouter, DanglingDropMiddleware:
+		return nil
+	default:
+		return fmt.Errorf("danglingReferences: invalid policy %q", policy)
+	}
+}
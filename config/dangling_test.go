@@ -0,0 +1,18 @@
+package config
+
+import "testing"
+
+func TestValidateDanglingPolicy(t *testing.T) {
+	for _, p := range []string{"", DanglingWarn, DanglingDropRouter, DanglingDropMiddleware} {
+		if err := ValidateDanglingPolicy(p); err != nil {
+			t.Errorf("%q: unexpected error %v", p, err)
+		}
+	}
+	if err := ValidateDanglingPolicy("drop"); err == nil {
+		t.Error("expected error")
+	}
+	p := ProviderConfig{DanglingReferences: "ignore"}
+	if err := p.Normalize(); err == nil {
+		t.Error("expected Normalize to validate the dangling reference policy")
+	}
+}
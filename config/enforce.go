@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// Enforced middleware positions. Before and After take the anchor middleware
+// name after the colon, e.g. "before:auth".
+const (
+	PositionFirst  = "first"
+	PositionLast   = "last"
+	PositionBefore = "before"
+	PositionAfter  = "after"
+)
+
+// EnforceMiddlewaresConfig lists middlewares that must be present on every
+// HTTP and TCP router.
+type EnforceMiddlewaresConfig struct {
+	HTTP []EnforceMiddleware `json:"http,omitempty" yaml:"http,omitempty"`
+	TCP  []EnforceMiddleware `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+}
+
+// EnforceMiddleware places a middleware on every router not matched by Exclude.
+// Position defaults to last; a before or after anchor that the router does not
+// use also falls back to last.
+type EnforceMiddleware struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Position string `json:"position,omitempty" yaml:"position,omitempty"`
+	Exclude  string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// ParsePosition splits an enforced middleware position into its kind and anchor.
+func ParsePosition(position string) (kind, anchor string, err error) {
+	switch position {
+	case "", PositionLast:
+		return PositionLast, "", nil
+	case PositionFirst:
+		return PositionFirst, "", nil
+	}
+	kind, anchor, ok := strings.Cut(position, ":")
+	if !ok || (kind != PositionBefore && kind != PositionAfter) {
+		return "", "", fmt.Errorf("invalid position %q", position)
+	}
+	if anchor == "" {
+		return "", "", fmt.Errorf("position %q requires a middleware name", position)
+	}
+	return kind, anchor, nil
+}
+
+// Validate checks that every enforced middleware has a name, a valid position
+// and a valid exclude matcher.
+func (e *EnforceMiddlewaresConfig) Validate() error {
+	if e == nil {
+		return nil
+	}
+	if err := validateEnforced("http", e.HTTP); err != nil {
+		return err
+	}
+	return validateEnforced("tcp", e.TCP)
+}
+
+func validateEnforced(proto string, list []EnforceMiddleware) error {
+	for i, m := range list {
+		if m.Name == "" {
+			return fmt.Errorf("enforceMiddlewares.%s[%d].name: required", proto, i)
+		}
+		if _, _, err := ParsePosition(m.Position); err != nil {
+			return fmt.Errorf("enforceMiddlewares.%s[%d].position: %w", proto, i, err)
+		}
+		if _, err := rules.Compile(m.Exclude); err != nil {
+			return fmt.Errorf("enforceMiddlewares.%s[%d].exclude: %w", proto, i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestParsePosition(t *testing.T) {
+	cases := []struct {
+		in, kind, anchor string
+		wantErr          bool
+	}{
+		{in: "", kind: PositionLast},
+		{in: "last", kind: PositionLast},
+		{in: "first", kind: PositionFirst},
+		{in: "before:auth", kind: PositionBefore, anchor: "auth"},
+		{in: "after:auth@file", kind: PositionAfter, anchor: "auth@file"},
+		{in: "after:", wantErr: true},
+		{in: "middle", wantErr: true},
+		{in: "around:auth", wantErr: true},
+	}
+	for _, tt := range cases {
+		kind, anchor, err := ParsePosition(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err=%v wantErr=%v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if kind != tt.kind || anchor != tt.anchor {
+			t.Errorf("%q: got (%q, %q) want (%q, %q)", tt.in, kind, anchor, tt.kind, tt.anchor)
+		}
+	}
+}
+
+func TestEnforceMiddlewaresConfig_Validate(t *testing.T) {
+	var nilCfg *EnforceMiddlewaresConfig
+	if err := nilCfg.Validate(); err != nil {
+		t.Errorf("nil config: unexpected error %v", err)
+	}
+	valid := &EnforceMiddlewaresConfig{HTTP: []EnforceMiddleware{{Name: "auth", Position: "first"}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, cfg := range []*EnforceMiddlewaresConfig{
+		{HTTP: []EnforceMiddleware{{Position: "first"}}},
+		{TCP: []EnforceMiddleware{{Name: "allow", Position: "sideways"}}},
+		{HTTP: []EnforceMiddleware{{Name: "auth", Exclude: "Name(`api`"}}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+	p := ProviderConfig{EnforceMiddlewares: &EnforceMiddlewaresConfig{HTTP: []EnforceMiddleware{{}}}}
+	if err := p.Normalize(); err == nil {
+		t.Error("expected Normalize to validate enforceMiddlewares")
+	}
+}
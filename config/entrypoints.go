@@ -0,0 +1,21 @@
+package config
+
+// Unmapped entrypoint policies.
+const (
+	// UnmappedKeep keeps entrypoint names that have no mapping.
+	UnmappedKeep = "keep"
+	// UnmappedDrop removes entrypoint names that have no mapping from the router.
+	UnmappedDrop = "drop"
+	// UnmappedDropRouter removes routers that use an entrypoint with no mapping.
+	UnmappedDropRouter = "dropRouter"
+)
+
+// EntrypointMapConfig renames upstream entrypoints on HTTP, TCP and UDP routers.
+type EntrypointMapConfig struct {
+	// Mappings maps upstream entrypoint names to local entrypoint names.
+	Mappings map[string]string `json:"mappings,omitempty" yaml:"mappings,omitempty"`
+	// Unmapped selects what happens to names without a mapping (default keep).
+	Unmapped string `json:"unmapped,omitempty" yaml:"unmapped,omitempty"`
+	// Defaults are assigned to routers left without entrypoints.
+	Defaults []string `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+}
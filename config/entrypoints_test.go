@@ -0,0 +1,16 @@
+package config
+
+import "testing"
+
+func TestProviderConfig_NormalizeEntrypointMap(t *testing.T) {
+	for _, policy := range []string{"", UnmappedKeep, UnmappedDrop, UnmappedDropRouter} {
+		p := ProviderConfig{EntrypointMap: &EntrypointMapConfig{Unmapped: policy}}
+		if err := p.Normalize(); err != nil {
+			t.Errorf("policy %q: unexpected error: %v", policy, err)
+		}
+	}
+	p := ProviderConfig{EntrypointMap: &EntrypointMapConfig{Unmapped: "ignore"}}
+	if err := p.Normalize(); err == nil {
+		t.Error("expected error for invalid unmapped policy")
+	}
+}
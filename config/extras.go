@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// DecodeExtras decodes extra resources into typed definitions keyed by name.
+// Extras are either a list of objects carrying their name in a "name" field or
+// a map of name to object, like Traefik's file provider. Unknown fields are
+// rejected. Entries that fail to decode are skipped and returned as errors so
+// that callers can keep the valid ones. Extras that were already decoded are
+// returned as is.
+func DecodeExtras[T any](extras interface{}) (map[string]*T, []error) {
+	if decoded, ok := extras.(map[string]*T); ok {
+		return decoded, nil
+	}
+	out := map[string]*T{}
+	var errs []error
+	add := func(label, name string, item interface{}) {
+		if _, exists := out[name]; exists {
+			errs = append(errs, fmt.Errorf("%s: duplicate name %q", label, name))
+			return
+		}
+		v, err := decodeStrict[T](item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", label, err))
+			return
+		}
+		out[name] = v
+	}
+	switch t := extras.(type) {
+	case nil:
+	case []interface{}:
+		for i, item := range t {
+			label := fmt.Sprintf("[%d]", i)
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: expected an object, got %T", label, item))
+				continue
+			}
+			name, ok := obj["name"].(string)
+			if !ok || name == "" {
+				errs = append(errs, fmt.Errorf("%s: missing name", label))
+				continue
+			}
+			fields := make(map[string]interface{}, len(obj))
+			for k, v := range obj {
+				if k != "name" {
+					fields[k] = v
+				}
+			}
+			add(label, name, fields)
+		}
+	case map[string]interface{}:
+		for name, item := range t {
+			label := fmt.Sprintf("[%q]", name)
+			if _, ok := item.(map[string]interface{}); !ok {
+				errs = append(errs, fmt.Errorf("%s: expected an object, got %T", label, item))
+				continue
+			}
+			add(label, name, item)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("expected a list or a map, got %T", extras))
+	}
+	return out, errs
+}
+
+func decodeStrict[T any](v interface{}) (*T, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+)
+
+func TestDecodeExtras_ListAndMap(t *testing.T) {
+	list := []interface{}{
+		map[string]interface{}{"name": "api", "rule": "Host(`api`)", "service": "api"},
+	}
+	got, errs := DecodeExtras[dynamic.Router](list)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got["api"] == nil || got["api"].Rule != "Host(`api`)" {
+		t.Errorf("unexpected list result: %+v", got)
+	}
+
+	m := map[string]interface{}{
+		"web": map[string]interface{}{"loadBalancer": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"url": "http://web"}}}},
+	}
+	services, errs := DecodeExtras[dynamic.Service](m)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if services["web"] == nil || services["web"].LoadBalancer.Servers[0].URL != "http://web" {
+		t.Errorf("unexpected map result: %+v", services)
+	}
+
+	if got, errs := DecodeExtras[dynamic.Router](nil); len(got) != 0 || len(errs) != 0 {
+		t.Errorf("nil extras: got %v errs %v", got, errs)
+	}
+}
+
+func TestDecodeExtras_Errors(t *testing.T) {
+	list := []interface{}{
+		"not-an-object",
+		map[string]interface{}{"rule": "Host(`unnamed`)"},
+		map[string]interface{}{"name": "typo", "rulle": "Host(`x`)"},
+		map[string]interface{}{"name": "ok", "rule": "Host(`ok`)"},
+		map[string]interface{}{"name": "ok", "rule": "Host(`dup`)"},
+	}
+	got, errs := DecodeExtras[dynamic.Router](list)
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	if len(got) != 1 || got["ok"].Rule != "Host(`ok`)" {
+		t.Errorf("expected only the first valid entry, got %+v", got)
+	}
+
+	if _, errs := DecodeExtras[dynamic.Router](map[string]interface{}{"api": []interface{}{}}); len(errs) != 1 {
+		t.Errorf("expected error for non-object map entry, got %v", errs)
+	}
+	if _, errs := DecodeExtras[dynamic.Router]("api"); len(errs) != 1 {
+		t.Errorf("expected error for scalar extras, got %v", errs)
+	}
+}
+
+func TestProviderConfig_NormalizeValidatesExtras(t *testing.T) {
+	cases := []ProviderConfig{
+		{HTTP: &HTTPSection{Routers: &RoutersConfig{ExtraRoutes: []interface{}{map[string]interface{}{"rule": "Host(`x`)"}}}}},
+		{TCP: &TCPSection{Services: &ServicesConfig{ExtraServices: map[string]interface{}{"db": map[string]interface{}{"loadBalancer": "x"}}}}},
+		{UDP: &UDPSection{Routers: &UDPRoutersConfig{ExtraRoutes: []interface{}{map[string]interface{}{"name": "dns", "rule": "x"}}}}},
+		{HTTP: &HTTPSection{ServersTransports: &ServersTransportsConfig{ExtraServersTransports: []interface{}{map[string]interface{}{"name": "mtls", "rootCA": "ca.pem"}}}}},
+		{HTTP: &HTTPSection{Models: &ModelsConfig{ExtraModels: map[string]interface{}{"websecure": map[string]interface{}{"middlewares": "auth"}}}}},
+	}
+	for i, p := range cases {
+		if err := p.Normalize(); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	valid := ProviderConfig{HTTP: &HTTPSection{Middlewares: &MiddlewaresConfig{
+		ExtraMiddlewares: map[string]interface{}{"strip": map[string]interface{}{"stripPrefix": map[string]interface{}{"prefixes": []interface{}{"/api"}}}},
+	}}}
+	if err := valid.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, ok := valid.HTTP.Middlewares.ExtraMiddlewares.(map[string]*dynamic.Middleware)
+	if !ok || decoded["strip"] == nil || decoded["strip"].StripPrefix == nil {
+		t.Fatalf("extras not stored decoded: %#v", valid.HTTP.Middlewares.ExtraMiddlewares)
+	}
+	if err := valid.Normalize(); err != nil {
+		t.Errorf("second Normalize: unexpected error: %v", err)
+	}
+}
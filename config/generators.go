@@ -0,0 +1,40 @@
+package config
+
+import "fmt"
+
+// Generator sources.
+const (
+	SourceRouters     = "routers"
+	SourceServices    = "services"
+	SourceMiddlewares = "middlewares"
+)
+
+// Generator creates one resource per upstream resource of Source selected by
+// Matcher. Name and every string in Template are rendered as templates with
+// the source resource's variables; generated resources are added before
+// provider stripping, like extras, and go through the rest of the pipeline.
+type Generator struct {
+	// Source is the kind of resource driving the generator. It defaults to the
+	// kind of the section the generator is declared in.
+	Source   string      `json:"source,omitempty" yaml:"source,omitempty"`
+	Matcher  string      `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Name     string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Template interface{} `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+func validateGenerators(path string, gens []Generator) error {
+	for i, g := range gens {
+		switch g.Source {
+		case "", SourceRouters, SourceServices, SourceMiddlewares:
+		default:
+			return fmt.Errorf("%s.generators[%d].source: invalid source %q", path, i, g.Source)
+		}
+		if g.Name == "" {
+			return fmt.Errorf("%s.generators[%d].name: required", path, i)
+		}
+		if _, ok := g.Template.(map[string]interface{}); !ok {
+			return fmt.Errorf("%s.generators[%d].template: expected an object, got %T", path, i, g.Template)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestValidateGenerators(t *testing.T) {
+	valid := []Generator{{Source: SourceServices, Name: "{{.Name}}-internal", Template: map[string]interface{}{"service": "{{.Name}}"}}}
+	if err := validateGenerators("http.routers", valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, gens := range [][]Generator{
+		{{Source: "tls", Name: "x", Template: map[string]interface{}{}}},
+		{{Template: map[string]interface{}{}}},
+		{{Name: "x", Template: "rule"}},
+	} {
+		if err := validateGenerators("http.routers", gens); err == nil {
+			t.Errorf("expected error for %+v", gens)
+		}
+	}
+
+	p := ProviderConfig{HTTP: &HTTPSection{Middlewares: &MiddlewaresConfig{Generators: []Generator{{}}}}}
+	if err := p.Normalize(); err == nil {
+		t.Error("expected Normalize to validate generators")
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import "fmt"
+
+// Merge conflict policies, applied when several providers define a resource
+// with the same name.
+const (
+	// MergeLastWins keeps the definition of the last provider.
+	MergeLastWins = "last-wins"
+	// MergeFirstWins keeps the definition of the first provider.
+	MergeFirstWins = "first-wins"
+	// MergeError reports the conflict and skips publishing the merged configuration.
+	MergeError = "error"
+	// MergeRename suffixes the later resource with its provider config name
+	// and rewrites the provider's references to it.
+	MergeRename = "rename"
+	// MergeDeep merges the later definition into the earlier one as a JSON
+	// merge patch.
+	MergeDeep = "deep-merge"
+	// MergeUnion combines load balancer services into one load balancer with
+	// the servers of all providers. Services only.
+	MergeUnion = "union"
+	// MergeWeighted replaces the service with a weighted service over one
+	// child per provider. Services only.
+	MergeWeighted = "weighted"
+)
+
+// MergeConfig sets the conflict policy per resource kind. Empty policies
+// default to MergeLastWins.
+type MergeConfig struct {
+	Routers           string `json:"routers,omitempty" yaml:"routers,omitempty"`
+	Services          string `json:"services,omitempty" yaml:"services,omitempty"`
+	Middlewares       string `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	ServersTransports string `json:"serversTransports,omitempty" yaml:"serversTransports,omitempty"`
+	// Models applies to HTTP models. Models are named after the entrypoint
+	// they apply to, so MergeRename is not valid.
+	Models string `json:"models,omitempty" yaml:"models,omitempty"`
+	// TLSOptions and TLSStores apply to TLS options and stores other than
+	// "default". Only MergeLastWins, MergeFirstWins, MergeError and MergeDeep
+	// are valid.
+	TLSOptions string `json:"tlsOptions,omitempty" yaml:"tlsOptions,omitempty"`
+	TLSStores  string `json:"tlsStores,omitempty" yaml:"tlsStores,omitempty"`
+	// TLSDefaults applies to the "default" TLS options and store, which every
+	// router and certificate without an explicit one uses. It defaults to
+	// MergeFirstWins so that a later provider cannot silently replace them.
+	TLSDefaults string `json:"tlsDefaults,omitempty" yaml:"tlsDefaults,omitempty"`
+	// Weights maps provider config names to their weight in services built by
+	// MergeWeighted. Providers not listed get weight 1.
+	Weights map[string]int `json:"weights,omitempty" yaml:"weights,omitempty"`
+	// Failovers are HTTP services built across providers at merge time.
+	Failovers []FailoverService `json:"failovers,omitempty" yaml:"failovers,omitempty"`
+}
+
+// FailoverService names a logical HTTP service served by the providers listed
+// in priority order. Each provider's service with that name is renamed
+// "<service>-<provider name>" and the service becomes a failover, nested when
+// there are more than two providers, from the first provider to the next.
+// Failover relies on the health checks of the children.
+type FailoverService struct {
+	Service   string   `json:"service,omitempty" yaml:"service,omitempty"`
+	Providers []string `json:"providers,omitempty" yaml:"providers,omitempty"`
+}
+
+// Validate checks every policy.
+func (m *MergeConfig) Validate() error {
+	if m == nil {
+		return nil
+	}
+	for _, p := range []struct{ field, policy string }{
+		{"routers", m.Routers},
+		{"services", m.Services},
+		{"middlewares", m.Middlewares},
+		{"serversTransports", m.ServersTransports},
+	} {
+		if err := validateMergePolicy(p.policy); err != nil {
+			return fmt.Errorf("merge.%s: %w", p.field, err)
+		}
+		if p.field != "services" && (p.policy == MergeUnion || p.policy == MergeWeighted) {
+			return fmt.Errorf("merge.%s: policy %q only applies to services", p.field, p.policy)
+		}
+	}
+	for _, p := range []struct{ field, policy string }{
+		{"models", m.Models},
+		{"tlsOptions", m.TLSOptions},
+		{"tlsStores", m.TLSStores},
+		{"tlsDefaults", m.TLSDefaults},
+	} {
+		switch p.policy {
+		case "", MergeLastWins, MergeFirstWins, MergeError, MergeDeep:
+		default:
+			return fmt.Errorf("merge.%s: invalid policy %q", p.field, p.policy)
+		}
+	}
+	services := map[string]bool{}
+	for i, f := range m.Failovers {
+		if f.Service == "" {
+			return fmt.Errorf("merge.failovers[%d].service: required", i)
+		}
+		if services[f.Service] {
+			return fmt.Errorf("merge.failovers[%d].service: duplicate service %q", i, f.Service)
+		}
+		services[f.Service] = true
+		if len(f.Providers) < 2 {
+			return fmt.Errorf("merge.failovers[%d].providers: at least two providers required", i)
+		}
+		seen := map[string]bool{}
+		for _, p := range f.Providers {
+			if seen[p] {
+				return fmt.Errorf("merge.failovers[%d].providers: duplicate provider %q", i, p)
+			}
+			seen[p] = true
+		}
+	}
+	for name, w := range m.Weights {
+		if w < 0 {
+			return fmt.Errorf("merge.weights.%s: must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// Weight returns the weight of a provider in services built by MergeWeighted.
+func (m *MergeConfig) Weight(provider string) int {
+	if m != nil {
+		if w, ok := m.Weights[provider]; ok {
+			return w
+		}
+	}
+	return 1
+}
+
+func validateMergePolicy(policy string) error {
+	switch policy {
+	case "", MergeLastWins, MergeFirstWins, MergeError, MergeRename, MergeDeep, MergeUnion, MergeWeighted:
+		return nil
+	default:
+		return fmt.Errorf("invalid policy %q", policy)
+	}
+}
+
+// policy returns p, or MergeLastWins when p is empty.
+func policy(p string) string {
+	if p == "" {
+		return MergeLastWins
+	}
+	return p
+}
+
+// RoutersPolicy returns the conflict policy for routers.
+func (m *MergeConfig) RoutersPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.Routers)
+}
+
+// ServicesPolicy returns the conflict policy for services.
+func (m *MergeConfig) ServicesPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.Services)
+}
+
+// MiddlewaresPolicy returns the conflict policy for middlewares.
+func (m *MergeConfig) MiddlewaresPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.Middlewares)
+}
+
+// ServersTransportsPolicy returns the conflict policy for servers transports.
+func (m *MergeConfig) ServersTransportsPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.ServersTransports)
+}
+
+// ModelsPolicy returns the conflict policy for HTTP models.
+func (m *MergeConfig) ModelsPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.Models)
+}
+
+// TLSOptionsPolicy returns the conflict policy for TLS options other than "default".
+func (m *MergeConfig) TLSOptionsPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.TLSOptions)
+}
+
+// TLSStoresPolicy returns the conflict policy for TLS stores other than "default".
+func (m *MergeConfig) TLSStoresPolicy() string {
+	if m == nil {
+		return MergeLastWins
+	}
+	return policy(m.TLSStores)
+}
+
+// TLSDefaultsPolicy returns the conflict policy for the "default" TLS options
+// and store.
+func (m *MergeConfig) TLSDefaultsPolicy() string {
+	if m == nil || m.TLSDefaults == "" {
+		return MergeFirstWins
+	}
+	return m.TLSDefaults
+}
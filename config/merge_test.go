@@ -0,0 +1,56 @@
+package config
+
+import "testing"
+
+func TestMergeConfig(t *testing.T) {
+	var m *MergeConfig
+	if err := m.Validate(); err != nil || m.RoutersPolicy() != MergeLastWins || m.ServersTransportsPolicy() != MergeLastWins {
+		t.Error("nil config must default to last-wins")
+	}
+	m = &MergeConfig{Routers: MergeError, Services: MergeRename, Middlewares: MergeDeep}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.RoutersPolicy() != MergeError || m.ServicesPolicy() != MergeRename || m.MiddlewaresPolicy() != MergeDeep || m.ServersTransportsPolicy() != MergeLastWins {
+		t.Errorf("unexpected policies: %+v", m)
+	}
+	for _, invalid := range []*MergeConfig{
+		{ServersTransports: "newest"},
+		{Routers: MergeUnion},
+		{Middlewares: MergeWeighted},
+		{Models: MergeRename},
+		{TLSOptions: MergeRename},
+		{TLSStores: MergeUnion},
+		{TLSDefaults: "newest"},
+		{Weights: map[string]int{"east": -1}},
+		{Failovers: []FailoverService{{Providers: []string{"east", "west"}}}},
+		{Failovers: []FailoverService{{Service: "api", Providers: []string{"east"}}}},
+		{Failovers: []FailoverService{{Service: "api", Providers: []string{"east", "east"}}}},
+		{Failovers: []FailoverService{{Service: "api", Providers: []string{"east", "west"}}, {Service: "api", Providers: []string{"west", "east"}}}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("%+v: expected error", invalid)
+		}
+	}
+	m = &MergeConfig{Services: MergeWeighted, Weights: map[string]int{"east": 3}}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Weight("east") != 3 || m.Weight("west") != 1 || (*MergeConfig)(nil).Weight("east") != 1 {
+		t.Error("unexpected weights")
+	}
+}
+
+func TestMergeConfig_TLSPolicies(t *testing.T) {
+	var m *MergeConfig
+	if m.ModelsPolicy() != MergeLastWins || m.TLSOptionsPolicy() != MergeLastWins || m.TLSStoresPolicy() != MergeLastWins || m.TLSDefaultsPolicy() != MergeFirstWins {
+		t.Error("unexpected default TLS policies")
+	}
+	m = &MergeConfig{Models: MergeFirstWins, TLSOptions: MergeDeep, TLSStores: MergeError, TLSDefaults: MergeLastWins}
+	if err := m.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.ModelsPolicy() != MergeFirstWins || m.TLSOptionsPolicy() != MergeDeep || m.TLSStoresPolicy() != MergeError || m.TLSDefaultsPolicy() != MergeLastWins {
+		t.Errorf("unexpected policies: %+v", m)
+	}
+}
@@ -2,7 +2,11 @@ package config
 
 // MiddlewaresConfig holds discovery and matcher settings for middlewares.
 type MiddlewaresConfig struct {
-	Discover         bool          `json:"discover,omitempty" yaml:"discover,omitempty"`
-	Matcher          string        `json:"matcher,omitempty" yaml:"matcher,omitempty"`
-	ExtraMiddlewares []interface{} `json:"extraMiddlewares,omitempty" yaml:"extraMiddlewares,omitempty"`
+	Discover   bool        `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher    string      `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Patches    []Patch     `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Remove     []Removal   `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Generators []Generator `json:"generators,omitempty" yaml:"generators,omitempty"`
+	// ExtraMiddlewares is a list of objects with a "name" field or a map of name to object.
+	ExtraMiddlewares interface{} `json:"extraMiddlewares,omitempty" yaml:"extraMiddlewares,omitempty"`
 }
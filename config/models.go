@@ -0,0 +1,11 @@
+package config
+
+// ModelsConfig holds discovery and matcher settings for HTTP models, the
+// default middlewares and TLS settings Traefik applies to the routers of the
+// entrypoint a model is named after.
+type ModelsConfig struct {
+	Discover bool   `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher  string `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	// ExtraModels is a list of objects with a "name" field or a map of name to object.
+	ExtraModels interface{} `json:"extraModels,omitempty" yaml:"extraModels,omitempty"`
+}
@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// Naming strategies.
+const (
+	// NamingStrip keeps the names with their @provider suffix stripped.
+	NamingStrip = "strip"
+	// NamingPrefix prepends Value to every name.
+	NamingPrefix = "prefix"
+	// NamingSuffix appends Value to every name.
+	NamingSuffix = "suffix"
+	// NamingTemplate renders Value as a template for every name.
+	NamingTemplate = "template"
+)
+
+// NamingConfig controls how the resources of a provider are named in the
+// generated configuration. References between resources of the provider are
+// rewritten to the new names.
+type NamingConfig struct {
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Value    string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Validate checks the naming strategy and that it has the value it needs.
+func (n *NamingConfig) Validate() error {
+	if n == nil {
+		return nil
+	}
+	switch n.Strategy {
+	case "", NamingStrip:
+		return nil
+	case NamingPrefix, NamingSuffix, NamingTemplate:
+		if n.Value == "" {
+			return fmt.Errorf("naming.value: required for strategy %q", n.Strategy)
+		}
+		return nil
+	default:
+		return fmt.Errorf("naming.strategy: invalid strategy %q", n.Strategy)
+	}
+}
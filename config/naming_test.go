@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestNamingConfig_Validate(t *testing.T) {
+	valid := []*NamingConfig{
+		nil,
+		{},
+		{Strategy: NamingStrip},
+		{Strategy: NamingPrefix, Value: "prov1-"},
+		{Strategy: NamingTemplate, Value: "{{.Name}}-{{.ProviderConfigName}}"},
+	}
+	for _, n := range valid {
+		if err := n.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", n, err)
+		}
+	}
+	invalid := []*NamingConfig{
+		{Strategy: NamingSuffix},
+		{Strategy: "namespace", Value: "x"},
+	}
+	for _, n := range invalid {
+		if err := n.Validate(); err == nil {
+			t.Errorf("%+v: expected error", n)
+		}
+	}
+	p := ProviderConfig{Naming: &NamingConfig{Strategy: NamingPrefix}}
+	if err := p.Normalize(); err == nil {
+		t.Error("expected Normalize to validate naming")
+	}
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/traefik/genconf/dynamic"
+)
+
+// Normalize converts loosely-typed values decoded from the static configuration
+// into their canonical form and validates them. It is called once when the
+// plugin is created so that the parsing pipeline only sees string or []string
+// override values.
+func (p *ProviderConfig) Normalize() error {
+	if p.EntrypointMap != nil {
+		switch p.EntrypointMap.Unmapped {
+		case "", UnmappedKeep, UnmappedDrop, UnmappedDropRouter:
+		default:
+			return fmt.Errorf("entrypointMap.unmapped: invalid policy %q", p.EntrypointMap.Unmapped)
+		}
+	}
+	if err := p.EnforceMiddlewares.Validate(); err != nil {
+		return err
+	}
+	if err := p.Naming.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateDanglingPolicy(p.DanglingReferences); err != nil {
+		return err
+	}
+	for i, provider := range p.PreserveProviders {
+		if provider == "" || strings.Contains(provider, "@") {
+			return fmt.Errorf("preserveProviders[%d]: invalid provider %q", i, provider)
+		}
+	}
+	if err := p.decodeExtras(); err != nil {
+		return err
+	}
+	if p.HTTP != nil {
+		if err := normalizeRouters("http.routers", p.HTTP.Routers); err != nil {
+			return err
+		}
+		if p.HTTP.Routers != nil {
+			if err := p.HTTP.Routers.HTTPSRedirect.Validate(); err != nil {
+				return err
+			}
+		}
+		if err := normalizeServices("http.services", p.HTTP.Services); err != nil {
+			return err
+		}
+		if p.HTTP.Middlewares != nil {
+			if err := validateRemovals("http.middlewares", p.HTTP.Middlewares.Remove); err != nil {
+				return err
+			}
+			if err := validateGenerators("http.middlewares", p.HTTP.Middlewares.Generators); err != nil {
+				return err
+			}
+		}
+	}
+	if p.TCP != nil {
+		if err := normalizeRouters("tcp.routers", p.TCP.Routers); err != nil {
+			return err
+		}
+		if err := normalizeServices("tcp.services", p.TCP.Services); err != nil {
+			return err
+		}
+		if p.TCP.Middlewares != nil {
+			if err := validateRemovals("tcp.middlewares", p.TCP.Middlewares.Remove); err != nil {
+				return err
+			}
+			if err := validateGenerators("tcp.middlewares", p.TCP.Middlewares.Generators); err != nil {
+				return err
+			}
+		}
+	}
+	if p.UDP != nil {
+		if p.UDP.Routers != nil {
+			if err := normalizeEntrypoints("udp.routers", p.UDP.Routers.Overrides.Entrypoints); err != nil {
+				return err
+			}
+			if err := validateRemovals("udp.routers", p.UDP.Routers.Remove); err != nil {
+				return err
+			}
+			if err := validateGenerators("udp.routers", p.UDP.Routers.Generators); err != nil {
+				return err
+			}
+		}
+		if p.UDP.Services != nil {
+			if err := normalizeServers("udp.services", p.UDP.Services.Overrides.Servers); err != nil {
+				return err
+			}
+			if len(p.UDP.Services.Overrides.LoadBalancers) > 0 {
+				return fmt.Errorf("udp.services.overrides.loadBalancers: not supported for UDP services")
+			}
+			if len(p.UDP.Services.Overrides.Wraps) > 0 {
+				return fmt.Errorf("udp.services.overrides.wraps: not supported for UDP services")
+			}
+			if err := validateRemovals("udp.services", p.UDP.Services.Remove); err != nil {
+				return err
+			}
+			if err := validateGenerators("udp.services", p.UDP.Services.Generators); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeRouters(path string, rc *RoutersConfig) error {
+	if rc == nil {
+		return nil
+	}
+	if err := normalizeEntrypoints(path, rc.Overrides.Entrypoints); err != nil {
+		return err
+	}
+	if err := validateRemovals(path, rc.Remove); err != nil {
+		return err
+	}
+	if err := validateGenerators(path, rc.Generators); err != nil {
+		return err
+	}
+	for i := range rc.Overrides.Middlewares {
+		v, err := NormalizeValue(rc.Overrides.Middlewares[i].Value)
+		if err != nil {
+			return fmt.Errorf("%s.overrides.middlewares[%d].value: %w", path, i, err)
+		}
+		rc.Overrides.Middlewares[i].Value = v
+	}
+	return nil
+}
+
+func normalizeEntrypoints(path string, eps []OverrideEntrypoint) error {
+	for i := range eps {
+		v, err := NormalizeValue(eps[i].Value)
+		if err != nil {
+			return fmt.Errorf("%s.overrides.entrypoints[%d].value: %w", path, i, err)
+		}
+		eps[i].Value = v
+	}
+	return nil
+}
+
+func normalizeServices(path string, sc *ServicesConfig) error {
+	if sc == nil {
+		return nil
+	}
+	if err := normalizeServers(path, sc.Overrides.Servers); err != nil {
+		return err
+	}
+	if err := validateLoadBalancers(path, sc.Overrides.LoadBalancers); err != nil {
+		return err
+	}
+	if err := validateRemovals(path, sc.Remove); err != nil {
+		return err
+	}
+	if err := validateGenerators(path, sc.Generators); err != nil {
+		return err
+	}
+	return validateWraps(path, sc.Overrides.Wraps)
+}
+
+func validateWraps(path string, wraps []OverrideWrap) error {
+	for i, w := range wraps {
+		prefix := fmt.Sprintf("%s.overrides.wraps[%d]", path, i)
+		switch w.Type {
+		case WrapWeighted:
+			if len(w.Services) == 0 {
+				return fmt.Errorf("%s.services: weighted wrap requires at least one service", prefix)
+			}
+			for j, s := range w.Services {
+				if s.Name == "" {
+					return fmt.Errorf("%s.services[%d].name: required", prefix, j)
+				}
+			}
+		case WrapFailover:
+			if w.Fallback == "" {
+				return fmt.Errorf("%s.fallback: failover wrap requires a fallback service", prefix)
+			}
+		case WrapMirroring:
+			if len(w.Mirrors) == 0 {
+				return fmt.Errorf("%s.mirrors: mirroring wrap requires at least one mirror", prefix)
+			}
+			for j, m := range w.Mirrors {
+				if m.Name == "" {
+					return fmt.Errorf("%s.mirrors[%d].name: required", prefix, j)
+				}
+				if m.Percent < 0 || m.Percent > 100 {
+					return fmt.Errorf("%s.mirrors[%d].percent: must be between 0 and 100", prefix, j)
+				}
+			}
+		default:
+			return fmt.Errorf("%s.type: invalid type %q", prefix, w.Type)
+		}
+	}
+	return nil
+}
+
+func validateLoadBalancers(path string, lbs []OverrideLoadBalancer) error {
+	for i, lb := range lbs {
+		if lb.Sticky != nil && lb.Sticky.Cookie != nil {
+			switch lb.Sticky.Cookie.SameSite {
+			case "", "none", "lax", "strict":
+			default:
+				return fmt.Errorf("%s.overrides.loadBalancers[%d].sticky.cookie.sameSite: invalid value %q", path, i, lb.Sticky.Cookie.SameSite)
+			}
+		}
+		if lb.ProxyProtocol != nil && (lb.ProxyProtocol.Version < 0 || lb.ProxyProtocol.Version > 2) {
+			return fmt.Errorf("%s.overrides.loadBalancers[%d].proxyProtocol.version: invalid version %d", path, i, lb.ProxyProtocol.Version)
+		}
+		if lb.TerminationDelay != nil && *lb.TerminationDelay < -1 {
+			return fmt.Errorf("%s.overrides.loadBalancers[%d].terminationDelay: invalid delay %d", path, i, *lb.TerminationDelay)
+		}
+	}
+	return nil
+}
+
+func normalizeServers(path string, servers []OverrideServer) error {
+	for i := range servers {
+		v, err := NormalizeValue(servers[i].Value)
+		if err != nil {
+			return fmt.Errorf("%s.overrides.servers[%d].value: %w", path, i, err)
+		}
+		servers[i].Value = v
+		switch servers[i].Strategy {
+		case "", ServerStrategyReplace, ServerStrategyAppend, ServerStrategyPrepend, ServerStrategyRewrite:
+			if l, ok := v.([]string); v == nil || ok && len(l) == 0 {
+				if servers[i].Strategy == "" {
+					return fmt.Errorf("%s.overrides.servers[%d].value: required", path, i)
+				}
+				return fmt.Errorf("%s.overrides.servers[%d].value: required for strategy %q", path, i, servers[i].Strategy)
+			}
+		case ServerStrategyRemove, ServerStrategyDedupe:
+		default:
+			return fmt.Errorf("%s.overrides.servers[%d].strategy: invalid strategy %q", path, i, servers[i].Strategy)
+		}
+	}
+	return nil
+}
+
+// NormalizeValue converts an override value into a string or []string.
+// Lists decoded from YAML, TOML, or JSON arrive as []interface{} and must only
+// contain strings. A nil value is returned unchanged.
+func NormalizeValue(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case nil, string, []string:
+		return t, nil
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for i, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("item %d: unsupported type %T, expected string", i, item)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T, expected string or list of strings", v)
+	}
+}
+
+// decodeExtras decodes every extra resource into its typed definition and
+// stores the result in place, so that polls only copy it.
+func (p *ProviderConfig) decodeExtras() error {
+	var errs []error
+	if h := p.HTTP; h != nil {
+		if h.Routers != nil {
+			errs = append(errs, normalizeExtras[dynamic.Router]("http.routers.extraRoutes", &h.Routers.ExtraRoutes))
+		}
+		if h.Services != nil {
+			errs = append(errs, normalizeExtras[dynamic.Service]("http.services.extraServices", &h.Services.ExtraServices))
+		}
+		if h.Middlewares != nil {
+			errs = append(errs, normalizeExtras[dynamic.Middleware]("http.middlewares.extraMiddlewares", &h.Middlewares.ExtraMiddlewares))
+		}
+		if h.Models != nil {
+			errs = append(errs, normalizeExtras[dynamic.Model]("http.models.extraModels", &h.Models.ExtraModels))
+		}
+		if h.ServersTransports != nil {
+			errs = append(errs, normalizeExtras[dynamic.ServersTransport]("http.serversTransports.extraServersTransports", &h.ServersTransports.ExtraServersTransports))
+		}
+	}
+	if t := p.TCP; t != nil {
+		if t.Routers != nil {
+			errs = append(errs, normalizeExtras[dynamic.TCPRouter]("tcp.routers.extraRoutes", &t.Routers.ExtraRoutes))
+		}
+		if t.Services != nil {
+			errs = append(errs, normalizeExtras[dynamic.TCPService]("tcp.services.extraServices", &t.Services.ExtraServices))
+		}
+		if t.Middlewares != nil {
+			errs = append(errs, normalizeExtras[dynamic.TCPMiddleware]("tcp.middlewares.extraMiddlewares", &t.Middlewares.ExtraMiddlewares))
+		}
+	}
+	if u := p.UDP; u != nil {
+		if u.Routers != nil {
+			errs = append(errs, normalizeExtras[dynamic.UDPRouter]("udp.routers.extraRoutes", &u.Routers.ExtraRoutes))
+		}
+		if u.Services != nil {
+			errs = append(errs, normalizeExtras[dynamic.UDPService]("udp.services.extraServices", &u.Services.ExtraServices))
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeExtras replaces *extras with its decoded map of name to definition.
+func normalizeExtras[T any](path string, extras *interface{}) error {
+	decoded, errs := DecodeExtras[T](*extras)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", path, errs[0])
+	}
+	if *extras != nil {
+		*extras = decoded
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "nil", in: nil, want: nil},
+		{name: "string", in: "web", want: "web"},
+		{name: "string slice", in: []string{"web"}, want: []string{"web"}},
+		{name: "decoded list", in: []interface{}{"web", "websecure"}, want: []string{"web", "websecure"}},
+		{name: "empty decoded list", in: []interface{}{}, want: []string{}},
+		{name: "list with non-string", in: []interface{}{"web", 1}, wantErr: true},
+		{name: "number", in: 42, wantErr: true},
+		{name: "map", in: map[string]interface{}{"a": "b"}, wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeValue(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err=%v wantErr=%v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderConfig_Normalize(t *testing.T) {
+	p := ProviderConfig{
+		HTTP: &HTTPSection{
+			Routers: &RoutersConfig{Overrides: RouterOverrides{
+				Entrypoints: []OverrideEntrypoint{{Value: []interface{}{"websecure"}}},
+				Middlewares: []OverrideMiddleware{{Value: []interface{}{"auth", "ratelimit"}}},
+			}},
+			Services: &ServicesConfig{Overrides: ServiceOverrides{
+				Servers: []OverrideServer{{Strategy: "replace", Value: []interface{}{"http://a"}}},
+			}},
+		},
+		UDP: &UDPSection{
+			Routers: &UDPRoutersConfig{Overrides: UDPOverrides{
+				Entrypoints: []OverrideEntrypoint{{Value: "dns"}},
+			}},
+		},
+	}
+	if err := p.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.HTTP.Routers.Overrides.Entrypoints[0].Value; !reflect.DeepEqual(got, []string{"websecure"}) {
+		t.Errorf("entrypoints value=%#v", got)
+	}
+	if got := p.HTTP.Routers.Overrides.Middlewares[0].Value; !reflect.DeepEqual(got, []string{"auth", "ratelimit"}) {
+		t.Errorf("middlewares value=%#v", got)
+	}
+	if got := p.HTTP.Services.Overrides.Servers[0].Value; !reflect.DeepEqual(got, []string{"http://a"}) {
+		t.Errorf("servers value=%#v", got)
+	}
+}
+
+func TestProviderConfig_NormalizeErrors(t *testing.T) {
+	cases := []ProviderConfig{
+		{TCP: &TCPSection{Routers: &RoutersConfig{Overrides: RouterOverrides{
+			Middlewares: []OverrideMiddleware{{Value: map[string]interface{}{"name": "auth"}}},
+		}}}},
+		{UDP: &UDPSection{Services: &UDPServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Value: []interface{}{1}}},
+		}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Strategy: "wrr"}},
+		}}}},
+		{HTTP: &HTTPSection{Routers: &RoutersConfig{HTTPSRedirect: &HTTPSRedirectConfig{Exclude: "Name(`api`"}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Matcher: "Name(`api`)"}},
+		}}}},
+		{TCP: &TCPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Strategy: ServerStrategyReplace, Value: []interface{}{}}},
+		}}}},
+		{UDP: &UDPSection{Services: &UDPServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Strategy: ServerStrategyPrepend}},
+		}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Servers: []OverrideServer{{Strategy: ServerStrategyRewrite, Pattern: "a"}},
+		}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			LoadBalancers: []OverrideLoadBalancer{{Sticky: &OverrideSticky{Cookie: &OverrideCookie{SameSite: "sometimes"}}}},
+		}}}},
+		{TCP: &TCPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			LoadBalancers: []OverrideLoadBalancer{{ProxyProtocol: &OverrideProxyProtocol{Version: 3}}},
+		}}}},
+		{UDP: &UDPSection{Services: &UDPServicesConfig{Overrides: ServiceOverrides{
+			LoadBalancers: []OverrideLoadBalancer{{Matcher: "Name(`dns`)"}},
+		}}}},
+		{UDP: &UDPSection{Services: &UDPServicesConfig{Overrides: ServiceOverrides{
+			Wraps: []OverrideWrap{{Type: WrapWeighted}},
+		}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Wraps: []OverrideWrap{{Type: "canary"}},
+		}}}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Wraps: []OverrideWrap{{Type: WrapFailover}},
+		}}}},
+		{PreserveProviders: []string{"internal", "file@x"}},
+		{HTTP: &HTTPSection{Services: &ServicesConfig{Overrides: ServiceOverrides{
+			Wraps: []OverrideWrap{{Type: WrapMirroring, Mirrors: []MirrorService{{Name: "shadow", Percent: 150}}}},
+		}}}},
+	}
+	for i, p := range cases {
+		if err := p.Normalize(); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
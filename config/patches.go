@@ -0,0 +1,18 @@
+package config
+
+// Patch applies a JSON Merge Patch (RFC 7386) and/or a JSON Patch (RFC 6902)
+// to the JSON form of every resource selected by Matcher. When both are set,
+// the merge patch is applied first.
+type Patch struct {
+	Matcher string           `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Merge   interface{}      `json:"merge,omitempty" yaml:"merge,omitempty"`
+	JSON    []PatchOperation `json:"json,omitempty" yaml:"json,omitempty"`
+}
+
+// PatchOperation is a single RFC 6902 operation (add, remove, replace, move, copy, test).
+type PatchOperation struct {
+	Op    string      `json:"op,omitempty" yaml:"op,omitempty"`
+	Path  string      `json:"path,omitempty" yaml:"path,omitempty"`
+	From  string      `json:"from,omitempty" yaml:"from,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// PruneConfig drops the services, middlewares and servers transports of the
+// merged configuration that no router uses. The keep matchers select, by
+// name, resources to export even when unreferenced; what they use is kept too.
+type PruneConfig struct {
+	KeepServices          string `json:"keepServices,omitempty" yaml:"keepServices,omitempty"`
+	KeepMiddlewares       string `json:"keepMiddlewares,omitempty" yaml:"keepMiddlewares,omitempty"`
+	KeepServersTransports string `json:"keepServersTransports,omitempty" yaml:"keepServersTransports,omitempty"`
+}
+
+// Validate checks that the keep matchers compile.
+func (p *PruneConfig) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, keep := range []struct{ field, matcher string }{
+		{"keepServices", p.KeepServices},
+		{"keepMiddlewares", p.KeepMiddlewares},
+		{"keepServersTransports", p.KeepServersTransports},
+	} {
+		if _, err := rules.Compile(keep.matcher); err != nil {
+			return fmt.Errorf("prune.%s: %w", keep.field, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// Defaults for generated HTTPS redirect routers.
+const (
+	DefaultRedirectEntrypoint = "web"
+	DefaultRedirectMiddleware = "redirect-to-https"
+	DefaultRedirectSuffix     = "-redirect"
+)
+
+// HTTPSRedirectConfig generates, for each HTTP router with TLS, a companion
+// router on the insecure entrypoints that redirects to HTTPS.
+type HTTPSRedirectConfig struct {
+	// Entrypoints are the insecure entrypoints of the generated routers (default ["web"]).
+	Entrypoints []string `json:"entrypoints,omitempty" yaml:"entrypoints,omitempty"`
+	// Middleware names the generated redirectScheme middleware (default "redirect-to-https").
+	// An existing redirectScheme middleware with that name is used as is.
+	Middleware string `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	// Suffix is appended to the TLS router name (default "-redirect").
+	Suffix    string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+	Port      string `json:"port,omitempty" yaml:"port,omitempty"`
+	Permanent bool   `json:"permanent,omitempty" yaml:"permanent,omitempty"`
+	// Exclude is a matcher; matching TLS routers get no redirect router.
+	Exclude string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// Validate checks that the exclude matcher compiles.
+func (rc *HTTPSRedirectConfig) Validate() error {
+	if rc == nil {
+		return nil
+	}
+	if _, err := rules.Compile(rc.Exclude); err != nil {
+		return fmt.Errorf("http.routers.httpsRedirect.exclude: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Removal takes resources, fields or list items away from every resource
+// selected by Matcher. Fields and list paths are JSON pointers into the JSON
+// form of the resource; missing fields and lists are ignored.
+type Removal struct {
+	Matcher string `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	// Resource drops the selected resources. Dropped middlewares are also
+	// removed from router middleware lists and chains.
+	Resource bool         `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Fields   []string     `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Items    []RemoveItem `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// RemoveItem removes the items of the list at Path that match Pattern. For
+// lists of objects, Field names the member matched against Pattern.
+type RemoveItem struct {
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Field   string `json:"field,omitempty" yaml:"field,omitempty"`
+}
+
+func validateRemovals(path string, removals []Removal) error {
+	for i, r := range removals {
+		for j, f := range r.Fields {
+			if !strings.HasPrefix(f, "/") {
+				return fmt.Errorf("%s.remove[%d].fields[%d]: invalid JSON pointer %q", path, i, j, f)
+			}
+		}
+		for j, item := range r.Items {
+			if !strings.HasPrefix(item.Path, "/") {
+				return fmt.Errorf("%s.remove[%d].items[%d].path: invalid JSON pointer %q", path, i, j, item.Path)
+			}
+			if _, err := regexp.Compile(item.Pattern); err != nil {
+				return fmt.Errorf("%s.remove[%d].items[%d].pattern: %w", path, i, j, err)
+			}
+		}
+	}
+	return nil
+}
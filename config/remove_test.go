@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestValidateRemovals(t *testing.T) {
+	valid := []Removal{{
+		Matcher: "Name(`api`)",
+		Fields:  []string{"/tls/domains"},
+		Items:   []RemoveItem{{Path: "/middlewares", Pattern: "^ipwhitelist"}},
+	}}
+	if err := validateRemovals("http.routers", valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, removals := range [][]Removal{
+		{{Fields: []string{"tls"}}},
+		{{Items: []RemoveItem{{Path: "middlewares"}}}},
+		{{Items: []RemoveItem{{Path: "/middlewares", Pattern: "("}}}},
+	} {
+		if err := validateRemovals("http.routers", removals); err == nil {
+			t.Errorf("expected error for %+v", removals)
+		}
+	}
+}
+
+func TestProviderConfig_NormalizeValidatesRemovals(t *testing.T) {
+	cases := []ProviderConfig{
+		{HTTP: &HTTPSection{Middlewares: &MiddlewaresConfig{Remove: []Removal{{Fields: []string{"x"}}}}}},
+		{TCP: &TCPSection{Routers: &RoutersConfig{Remove: []Removal{{Fields: []string{"x"}}}}}},
+		{UDP: &UDPSection{Services: &UDPServicesConfig{Remove: []Removal{{Fields: []string{"x"}}}}}},
+	}
+	for i, p := range cases {
+		if err := p.Normalize(); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -2,12 +2,20 @@ package config
 
 // RoutersConfig holds discovery, matcher, and override settings for routers.
 type RoutersConfig struct {
-	Discover             bool            `json:"discover,omitempty" yaml:"discover,omitempty"`
-	DiscoverPriority     bool            `json:"discoverPriority,omitempty" yaml:"discoverPriority,omitempty"`
-	Matcher              string          `json:"matcher,omitempty" yaml:"matcher,omitempty"`
-	StripServiceProvider bool            `json:"stripServiceProvider,omitempty" yaml:"stripServiceProvider,omitempty"`
-	Overrides            RouterOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
-	ExtraRoutes          []interface{}   `json:"extraRoutes,omitempty" yaml:"extraRoutes,omitempty"`
+	Discover             bool                 `json:"discover,omitempty" yaml:"discover,omitempty"`
+	DiscoverPriority     bool                 `json:"discoverPriority,omitempty" yaml:"discoverPriority,omitempty"`
+	Matcher              string               `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	StripServiceProvider bool                 `json:"stripServiceProvider,omitempty" yaml:"stripServiceProvider,omitempty"`
+	Overrides            RouterOverrides      `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Patches              []Patch              `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Remove               []Removal            `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Generators           []Generator          `json:"generators,omitempty" yaml:"generators,omitempty"`
+	HTTPSRedirect        *HTTPSRedirectConfig `json:"httpsRedirect,omitempty" yaml:"httpsRedirect,omitempty"`
+	// IncludeDependencies adds the upstream services and middlewares used by
+	// the routers even when the service and middleware matchers exclude them.
+	IncludeDependencies bool `json:"includeDependencies,omitempty" yaml:"includeDependencies,omitempty"`
+	// ExtraRoutes is a list of objects with a "name" field or a map of name to object.
+	ExtraRoutes interface{} `json:"extraRoutes,omitempty" yaml:"extraRoutes,omitempty"`
 }
 
 // RouterOverrides defines override rules applied to matched routers.
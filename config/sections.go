@@ -1,11 +1,14 @@
 package config
 
-// HTTPSection controls discovery of HTTP routers, middlewares, and services.
+// HTTPSection controls discovery of HTTP routers, middlewares, services,
+// models, and servers transports.
 type HTTPSection struct {
-	Discover    bool               `json:"discover,omitempty" yaml:"discover,omitempty"`
-	Routers     *RoutersConfig     `json:"routers,omitempty" yaml:"routers,omitempty"`
-	Middlewares *MiddlewaresConfig `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
-	Services    *ServicesConfig    `json:"services,omitempty" yaml:"services,omitempty"`
+	Discover          bool                     `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Routers           *RoutersConfig           `json:"routers,omitempty" yaml:"routers,omitempty"`
+	Middlewares       *MiddlewaresConfig       `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Services          *ServicesConfig          `json:"services,omitempty" yaml:"services,omitempty"`
+	Models            *ModelsConfig            `json:"models,omitempty" yaml:"models,omitempty"`
+	ServersTransports *ServersTransportsConfig `json:"serversTransports,omitempty" yaml:"serversTransports,omitempty"`
 }
 
 // TCPSection controls discovery of TCP routers, middlewares, and services.
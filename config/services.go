@@ -2,29 +2,143 @@ package config
 
 // ServicesConfig holds discovery and override settings for services.
 type ServicesConfig struct {
-	Discover      bool             `json:"discover,omitempty" yaml:"discover,omitempty"`
-	Matcher       string           `json:"matcher,omitempty" yaml:"matcher,omitempty"`
-	Overrides     ServiceOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
-	ExtraServices []interface{}    `json:"extraServices,omitempty" yaml:"extraServices,omitempty"`
+	Discover   bool             `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher    string           `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Overrides  ServiceOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Patches    []Patch          `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Remove     []Removal        `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Generators []Generator      `json:"generators,omitempty" yaml:"generators,omitempty"`
+	// ExtraServices is a list of objects with a "name" field or a map of name to object.
+	ExtraServices interface{} `json:"extraServices,omitempty" yaml:"extraServices,omitempty"`
 }
 
-// ServiceOverrides defines how to override service backends and healthchecks.
+// ServiceOverrides defines how to override service backends, healthchecks,
+// load balancer behavior and service shape.
 type ServiceOverrides struct {
-	Servers      []OverrideServer      `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Healthchecks []OverrideHealthcheck `json:"healthchecks,omitempty" yaml:"healthchecks,omitempty"`
+	Servers       []OverrideServer       `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Healthchecks  []OverrideHealthcheck  `json:"healthchecks,omitempty" yaml:"healthchecks,omitempty"`
+	LoadBalancers []OverrideLoadBalancer `json:"loadBalancers,omitempty" yaml:"loadBalancers,omitempty"`
+	Wraps         []OverrideWrap         `json:"wraps,omitempty" yaml:"wraps,omitempty"`
 }
 
+// Server override strategies.
+const (
+	// ServerStrategyReplace replaces the servers with Value.
+	ServerStrategyReplace = "replace"
+	// ServerStrategyAppend adds Value after the existing servers.
+	ServerStrategyAppend = "append"
+	// ServerStrategyPrepend adds Value before the existing servers.
+	ServerStrategyPrepend = "prepend"
+	// ServerStrategyRemove removes servers whose URL or address matches Pattern.
+	ServerStrategyRemove = "remove"
+	// ServerStrategyRewrite replaces Pattern matches in server URLs or addresses with Value.
+	ServerStrategyRewrite = "rewrite"
+	// ServerStrategyDedupe removes duplicate servers, keeping the first occurrence.
+	ServerStrategyDedupe = "dedupe"
+)
+
 // OverrideServer configures server address overrides for matching services.
+// When Strategy is empty, a list Value replaces the servers and a string Value
+// is appended.
 type OverrideServer struct {
 	Strategy string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
 	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Pattern  string      `json:"pattern,omitempty" yaml:"pattern,omitempty"`
 	Matcher  string      `json:"matcher,omitempty" yaml:"matcher,omitempty"`
 }
 
 // OverrideHealthcheck overrides healthcheck settings for matching services.
+// Non-empty fields are applied, creating the healthcheck when the service has
+// none. Headers are merged into the existing headers. When Remove is true the
+// healthcheck is deleted and the other fields are ignored.
 type OverrideHealthcheck struct {
-	Path     string `json:"path,omitempty" yaml:"path,omitempty"`
-	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
-	Timeout  string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
-	Matcher  string `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Scheme          string            `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	Path            string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Method          string            `json:"method,omitempty" yaml:"method,omitempty"`
+	Port            int               `json:"port,omitempty" yaml:"port,omitempty"`
+	Interval        string            `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout         string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Hostname        string            `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	FollowRedirects *bool             `json:"followRedirects,omitempty" yaml:"followRedirects,omitempty"`
+	Remove          bool              `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Matcher         string            `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+}
+
+// OverrideLoadBalancer overrides load balancer settings for matching services.
+// Only the fields that are set are applied. Sticky, PassHostHeader,
+// ResponseForwarding and ServersTransport apply to HTTP services;
+// TerminationDelay and ProxyProtocol apply to TCP services.
+type OverrideLoadBalancer struct {
+	Sticky             *OverrideSticky             `json:"sticky,omitempty" yaml:"sticky,omitempty"`
+	PassHostHeader     *bool                       `json:"passHostHeader,omitempty" yaml:"passHostHeader,omitempty"`
+	ResponseForwarding *OverrideResponseForwarding `json:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty"`
+	ServersTransport   string                      `json:"serversTransport,omitempty" yaml:"serversTransport,omitempty"`
+	TerminationDelay   *int                        `json:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty"`
+	ProxyProtocol      *OverrideProxyProtocol      `json:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty"`
+	Matcher            string                      `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+}
+
+// OverrideSticky configures sticky sessions. When Remove is true sticky
+// sessions are disabled and Cookie is ignored.
+type OverrideSticky struct {
+	Cookie *OverrideCookie `json:"cookie,omitempty" yaml:"cookie,omitempty"`
+	Remove bool            `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// OverrideCookie configures the sticky session cookie. Unset fields keep their
+// current value.
+type OverrideCookie struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Secure   *bool  `json:"secure,omitempty" yaml:"secure,omitempty"`
+	HTTPOnly *bool  `json:"httpOnly,omitempty" yaml:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty" yaml:"sameSite,omitempty"`
+}
+
+// OverrideResponseForwarding configures how responses are forwarded to clients.
+type OverrideResponseForwarding struct {
+	FlushInterval string `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+}
+
+// OverrideProxyProtocol configures the PROXY protocol sent to TCP servers.
+// A Version of 0 disables the PROXY protocol.
+type OverrideProxyProtocol struct {
+	Version int `json:"version" yaml:"version"`
+}
+
+// Service wrap types.
+const (
+	// WrapWeighted splits traffic between the origin and Services.
+	WrapWeighted = "weighted"
+	// WrapFailover sends traffic to Fallback when the origin is unhealthy.
+	WrapFailover = "failover"
+	// WrapMirroring mirrors traffic sent to the origin to Mirrors.
+	WrapMirroring = "mirroring"
+)
+
+// OverrideWrap rewrites matching HTTP load balancer services into a weighted,
+// failover or mirroring service. The original load balancer moves into a child
+// service named after Origin ("$1" is replaced with the service name, default
+// "$1-origin"), so routers keep referencing the original name.
+type OverrideWrap struct {
+	Type        string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Origin      string            `json:"origin,omitempty" yaml:"origin,omitempty"`
+	Weight      *int              `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Services    []WeightedService `json:"services,omitempty" yaml:"services,omitempty"`
+	Fallback    string            `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+	Mirrors     []MirrorService   `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	MaxBodySize *int64            `json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+	Matcher     string            `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+}
+
+// WeightedService is a service added next to the origin of a weighted wrap.
+type WeightedService struct {
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	Weight *int   `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// MirrorService is a service receiving Percent of the traffic of a mirroring wrap.
+type MirrorService struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Percent int    `json:"percent,omitempty" yaml:"percent,omitempty"`
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/traefik/genconf/dynamic"
+	tlstypes "github.com/traefik/genconf/dynamic/tls"
+)
+
+// ServersTransportsConfig holds discovery and override settings for HTTP
+// servers transports.
+type ServersTransportsConfig struct {
+	Discover  bool                       `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher   string                     `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Overrides []OverrideServersTransport `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	// ExtraServersTransports is a list of objects with a "name" field or a map of name to object.
+	ExtraServersTransports interface{} `json:"extraServersTransports,omitempty" yaml:"extraServersTransports,omitempty"`
+}
+
+// OverrideServersTransport overrides settings of matching servers transports.
+// Only the fields that are set are applied. RootCAs and Certificates replace
+// the existing lists, which is how upstream file paths are pointed at local
+// copies; non-empty ForwardingTimeouts fields replace the existing ones.
+type OverrideServersTransport struct {
+	ServerName          string                      `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	InsecureSkipVerify  *bool                       `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	RootCAs             []string                    `json:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
+	Certificates        []tlstypes.Certificate      `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	MaxIdleConnsPerHost *int                        `json:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty"`
+	ForwardingTimeouts  *dynamic.ForwardingTimeouts `json:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty"`
+	DisableHTTP2        *bool                       `json:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty"` //nolint:tagliatelle
+	PeerCertURI         string                      `json:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty"`
+	Matcher             string                      `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+}
@@ -2,10 +2,17 @@ package config
 
 // UDPRoutersConfig holds discovery, matching, and overrides for UDP routers.
 type UDPRoutersConfig struct {
-	Discover    bool          `json:"discover,omitempty" yaml:"discover,omitempty"`
-	Matcher     string        `json:"matcher,omitempty" yaml:"matcher,omitempty"`
-	Overrides   UDPOverrides  `json:"overrides,omitempty" yaml:"overrides,omitempty"`
-	ExtraRoutes []interface{} `json:"extraRoutes,omitempty" yaml:"extraRoutes,omitempty"`
+	Discover   bool         `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher    string       `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Overrides  UDPOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Patches    []Patch      `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Remove     []Removal    `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Generators []Generator  `json:"generators,omitempty" yaml:"generators,omitempty"`
+	// IncludeDependencies adds the upstream services used by the routers even
+	// when the service matcher excludes them.
+	IncludeDependencies bool `json:"includeDependencies,omitempty" yaml:"includeDependencies,omitempty"`
+	// ExtraRoutes is a list of objects with a "name" field or a map of name to object.
+	ExtraRoutes interface{} `json:"extraRoutes,omitempty" yaml:"extraRoutes,omitempty"`
 }
 
 // UDPOverrides defines overrides applied to matched UDP routers.
@@ -17,8 +24,12 @@ type UDPOverrides struct {
 
 // UDPServicesConfig holds discovery, matching, and overrides for UDP services.
 type UDPServicesConfig struct {
-	Discover      bool             `json:"discover,omitempty" yaml:"discover,omitempty"`
-	Matcher       string           `json:"matcher,omitempty" yaml:"matcher,omitempty"`
-	Overrides     ServiceOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
-	ExtraServices []interface{}    `json:"extraServices,omitempty" yaml:"extraServices,omitempty"`
+	Discover   bool             `json:"discover,omitempty" yaml:"discover,omitempty"`
+	Matcher    string           `json:"matcher,omitempty" yaml:"matcher,omitempty"`
+	Overrides  ServiceOverrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Patches    []Patch          `json:"patches,omitempty" yaml:"patches,omitempty"`
+	Remove     []Removal        `json:"remove,omitempty" yaml:"remove,omitempty"`
+	Generators []Generator      `json:"generators,omitempty" yaml:"generators,omitempty"`
+	// ExtraServices is a list of objects with a "name" field or a map of name to object.
+	ExtraServices interface{} `json:"extraServices,omitempty" yaml:"extraServices,omitempty"`
 }
@@ -95,17 +95,17 @@ func parseDynamicConfiguration(body []byte, providerCfg *config.ProviderConfig)
 
 	// HTTP
 	if providerCfg.HTTP.Discover {
-		parsers.ParseHTTPConfig(raw, httpConfig, providerCfg.HTTP, providerCfg.Matcher, providerCfg.Tunnels)
+		parsers.ParseHTTPConfig(raw, httpConfig, providerCfg)
 	}
 
 	// TCP
 	if providerCfg.TCP.Discover {
-		parsers.ParseTCPConfig(raw, tcpConfig, providerCfg.TCP, providerCfg.Matcher, providerCfg.Tunnels)
+		parsers.ParseTCPConfig(raw, tcpConfig, providerCfg)
 	}
 
 	// UDP
 	if providerCfg.UDP.Discover {
-		parsers.ParseUDPConfig(raw, udpConfig, providerCfg.UDP, providerCfg.Matcher)
+		parsers.ParseUDPConfig(raw, udpConfig, providerCfg)
 	}
 
 	// TLS
@@ -119,6 +119,7 @@ func parseDynamicConfiguration(body []byte, providerCfg *config.ProviderConfig)
 		UDP:  udpConfig,
 		TLS:  tlsConfig,
 	}
+	parsers.ApplyNaming(cfg, providerCfg)
 
 	return cfg, nil
 }
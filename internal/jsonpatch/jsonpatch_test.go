@@ -0,0 +1,94 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{`["a"]`, `{"a":"b"}`, `{"a":"b"}`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+	}
+	for _, c := range cases {
+		got := MergePatch(decode(t, c.target), decode(t, c.patch))
+		if !reflect.DeepEqual(got, decode(t, c.want)) {
+			t.Errorf("MergePatch(%s, %s)=%v want %s", c.target, c.patch, got, c.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name string
+		doc  string
+		ops  []Operation
+		want string
+	}{
+		{"add member", `{"a":1}`, []Operation{{Op: "add", Path: "/b", Value: "x"}}, `{"a":1,"b":"x"}`},
+		{"add array end", `{"a":["x"]}`, []Operation{{Op: "add", Path: "/a/-", Value: "y"}}, `{"a":["x","y"]}`},
+		{"add array insert", `{"a":["x","z"]}`, []Operation{{Op: "add", Path: "/a/1", Value: "y"}}, `{"a":["x","y","z"]}`},
+		{"remove member", `{"a":1,"b":2}`, []Operation{{Op: "remove", Path: "/b"}}, `{"a":1}`},
+		{"remove array item", `{"a":["x","y","z"]}`, []Operation{{Op: "remove", Path: "/a/1"}}, `{"a":["x","z"]}`},
+		{"replace nested", `{"a":{"b":"c"}}`, []Operation{{Op: "replace", Path: "/a/b", Value: "d"}}, `{"a":{"b":"d"}}`},
+		{"move", `{"a":{"b":"c"}}`, []Operation{{Op: "move", From: "/a/b", Path: "/d"}}, `{"a":{},"d":"c"}`},
+		{"copy", `{"a":["x"]}`, []Operation{{Op: "copy", From: "/a", Path: "/b"}}, `{"a":["x"],"b":["x"]}`},
+		{"test then replace", `{"a":"x"}`, []Operation{{Op: "test", Path: "/a", Value: "x"}, {Op: "replace", Path: "/a", Value: "y"}}, `{"a":"y"}`},
+		{"escaped pointer", `{"a/b":{"c~d":1}}`, []Operation{{Op: "replace", Path: "/a~1b/c~0d", Value: 2.0}}, `{"a/b":{"c~d":2}}`},
+		{"replace whole document", `{"a":1}`, []Operation{{Op: "replace", Path: "", Value: map[string]interface{}{"b": 2.0}}}, `{"b":2}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Apply(decode(t, c.doc), c.ops)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, decode(t, c.want)) {
+				t.Fatalf("got %v want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		doc  string
+		op   Operation
+	}{
+		{"unknown op", `{}`, Operation{Op: "frobnicate", Path: "/a"}},
+		{"remove missing", `{}`, Operation{Op: "remove", Path: "/a"}},
+		{"replace missing", `{}`, Operation{Op: "replace", Path: "/a", Value: 1}},
+		{"add missing parent", `{}`, Operation{Op: "add", Path: "/a/b", Value: 1}},
+		{"index out of bounds", `{"a":[]}`, Operation{Op: "add", Path: "/a/1", Value: 1}},
+		{"leading zero index", `{"a":["x","y"]}`, Operation{Op: "remove", Path: "/a/01"}},
+		{"invalid pointer", `{}`, Operation{Op: "add", Path: "a", Value: 1}},
+		{"test mismatch", `{"a":"x"}`, Operation{Op: "test", Path: "/a", Value: "y"}},
+		{"move into child", `{"a":{}}`, Operation{Op: "move", From: "/a", Path: "/a/b"}},
+		{"remove document", `{}`, Operation{Op: "remove", Path: ""}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Apply(decode(t, c.doc), []Operation{c.op}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
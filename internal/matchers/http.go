@@ -2,6 +2,8 @@
 package matchers
 
 import (
+	"strings"
+
 	"github.com/traefik/genconf/dynamic"
 	"github.com/zalbiraw/traefikprovider/config"
 	"github.com/zalbiraw/traefikprovider/internal/rules"
@@ -84,3 +86,56 @@ func HTTPMiddlewares(middlewares map[string]*dynamic.Middleware, cfg *config.Mid
 	}
 	return result
 }
+
+// HTTPModels filters HTTP models based on `cfg.Matcher` and optional provider-level matcher.
+// A model is named after the entrypoint it applies to, so it also matches
+// Entrypoint rules on that name.
+func HTTPModels(models map[string]*dynamic.Model, cfg *config.ModelsConfig, providerMatcher string) map[string]*dynamic.Model {
+	result := make(map[string]*dynamic.Model)
+	combined := combineRules(providerMatcher, cfg.Matcher)
+	if combined == "" {
+		return models
+	}
+	prog, err := compileRule(combined)
+	if err != nil {
+		return result
+	}
+	for name, model := range models {
+		entrypoint := name
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			entrypoint = name[:i]
+		}
+		ctx := rules.Context{
+			Name:        name,
+			Provider:    extractProviderFromName(name),
+			Entrypoints: []string{entrypoint},
+		}
+		if prog.Match(ctx) {
+			result[name] = model
+		}
+	}
+	return result
+}
+
+// HTTPServersTransports filters HTTP servers transports based on `cfg.Matcher` and optional provider-level matcher.
+func HTTPServersTransports(transports map[string]*dynamic.ServersTransport, cfg *config.ServersTransportsConfig, providerMatcher string) map[string]*dynamic.ServersTransport {
+	result := make(map[string]*dynamic.ServersTransport)
+	combined := combineRules(providerMatcher, cfg.Matcher)
+	if combined == "" {
+		return transports
+	}
+	prog, err := compileRule(combined)
+	if err != nil {
+		return result
+	}
+	for name, st := range transports {
+		ctx := rules.Context{
+			Name:     name,
+			Provider: extractProviderFromName(name),
+		}
+		if prog.Match(ctx) {
+			result[name] = st
+		}
+	}
+	return result
+}
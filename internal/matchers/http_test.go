@@ -309,3 +309,36 @@ func TestMatchHTTPMiddlewares(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPModels(t *testing.T) {
+	models := map[string]*dynamic.Model{
+		"websecure@file": {Middlewares: []string{"auth"}},
+		"web@file":       {},
+		"websecure@k8s":  {},
+	}
+	got := HTTPModels(models, &config.ModelsConfig{Matcher: "Entrypoint(`websecure`)"}, "Provider(`file`)")
+	if len(got) != 1 || got["websecure@file"] == nil {
+		t.Fatalf("expected only websecure@file, got %v", got)
+	}
+	if got := HTTPModels(models, &config.ModelsConfig{}, ""); len(got) != 3 {
+		t.Fatalf("expected all models without matcher, got %d", len(got))
+	}
+	if got := HTTPModels(models, &config.ModelsConfig{Matcher: "Entrypoint("}, ""); len(got) != 0 {
+		t.Fatalf("expected no models for an invalid matcher, got %d", len(got))
+	}
+}
+
+func TestHTTPServersTransports(t *testing.T) {
+	transports := map[string]*dynamic.ServersTransport{
+		"mtls@file":     {},
+		"insecure@file": {},
+		"mtls@docker":   {},
+	}
+	got := HTTPServersTransports(transports, &config.ServersTransportsConfig{Matcher: "Name(`mtls@file`)"}, "Provider(`file`)")
+	if len(got) != 1 || got["mtls@file"] == nil {
+		t.Fatalf("expected only mtls@file, got %v", got)
+	}
+	if got := HTTPServersTransports(transports, &config.ServersTransportsConfig{}, ""); len(got) != 3 {
+		t.Fatalf("expected all transports without matcher, got %d", len(got))
+	}
+}
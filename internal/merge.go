@@ -2,84 +2,536 @@
 package internal
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/traefik/genconf/dynamic"
 	tlstypes "github.com/traefik/genconf/dynamic/tls"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/overrides"
 )
 
+// Source is a configuration to merge with the provider config it was
+// generated from. Provider may be nil.
+type Source struct {
+	Provider *config.ProviderConfig
+	Config   *dynamic.Configuration
+}
+
+// Conflict describes a resource defined differently by two sources.
+type Conflict struct {
+	Kind   string
+	Name   string
+	First  string
+	Second string
+	Policy string
+	// Renamed is the new name of the second resource under MergeRename.
+	Renamed string
+	// Err is set when the definitions could not be deep-merged; the first one is kept.
+	Err error
+}
+
+func (c Conflict) Error() string {
+	msg := fmt.Sprintf("%s %q defined by providers %q and %q (%s)", c.Kind, c.Name, c.First, c.Second, c.Policy)
+	if c.Renamed != "" {
+		msg += fmt.Sprintf(": renamed to %q", c.Renamed)
+	}
+	if c.Err != nil {
+		msg += fmt.Sprintf(": %v", c.Err)
+	}
+	return msg
+}
+
+// MergeResult is the outcome of Merge.
+type MergeResult struct {
+	Config    *dynamic.Configuration
+	Conflicts []Conflict
+	// Warnings report merged resources that are valid but unlikely to work
+	// as intended, such as a failover primary without a health check.
+	Warnings []error
+
+	sources []Source
+	// owners maps a resource kind and name to the index of the source whose
+	// definition was kept.
+	owners map[string]map[string]int
+}
+
+// Failed reports whether a conflict was resolved with MergeError, in which
+// case the merged configuration must not be published.
+func (r *MergeResult) Failed() bool {
+	for _, c := range r.Conflicts {
+		if c.Policy == config.MergeError {
+			return true
+		}
+	}
+	return false
+}
+
+// Origin returns the provider config a router of the merged configuration
+// was kept from, or nil when it is unknown.
+func (r *MergeResult) Origin(protocol, router string) *config.ProviderConfig {
+	i, ok := r.owners[protocol+" router"][router]
+	if !ok {
+		return nil
+	}
+	return r.sources[i].Provider
+}
+
 // MergeConfigurations merges multiple dynamic.Configurations into one.
+// Later configurations override earlier ones on identical keys.
 func MergeConfigurations(configs ...*dynamic.Configuration) *dynamic.Configuration {
-	merged := &dynamic.Configuration{
-		HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{}, Services: map[string]*dynamic.Service{}, Middlewares: map[string]*dynamic.Middleware{}, ServersTransports: map[string]*dynamic.ServersTransport{}},
-		TCP:  &dynamic.TCPConfiguration{Routers: map[string]*dynamic.TCPRouter{}, Services: map[string]*dynamic.TCPService{}, Middlewares: map[string]*dynamic.TCPMiddleware{}},
-		UDP:  &dynamic.UDPConfiguration{Routers: map[string]*dynamic.UDPRouter{}, Services: map[string]*dynamic.UDPService{}},
-		TLS:  &dynamic.TLSConfiguration{Certificates: []*tlstypes.CertAndStores{}, Options: map[string]tlstypes.Options{}, Stores: map[string]tlstypes.Store{}},
-	}
+	sources := make([]Source, 0, len(configs))
 	for _, cfg := range configs {
-		if cfg == nil {
+		sources = append(sources, Source{Config: cfg})
+	}
+	return Merge(sources, nil).Config
+}
+
+// Merge merges the sources in order, resolving resources defined by several
+// sources with the policies of mc. Identical definitions are not conflicts.
+func Merge(sources []Source, mc *config.MergeConfig) *MergeResult {
+	m := &merger{
+		MergeResult: MergeResult{
+			Config: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{}, Services: map[string]*dynamic.Service{}, Middlewares: map[string]*dynamic.Middleware{}, Models: map[string]*dynamic.Model{}, ServersTransports: map[string]*dynamic.ServersTransport{}},
+				TCP:  &dynamic.TCPConfiguration{Routers: map[string]*dynamic.TCPRouter{}, Services: map[string]*dynamic.TCPService{}, Middlewares: map[string]*dynamic.TCPMiddleware{}},
+				UDP:  &dynamic.UDPConfiguration{Routers: map[string]*dynamic.UDPRouter{}, Services: map[string]*dynamic.UDPService{}},
+				TLS:  &dynamic.TLSConfiguration{Certificates: []*tlstypes.CertAndStores{}, Options: map[string]tlstypes.Options{}, Stores: map[string]tlstypes.Store{}},
+			},
+			sources: sources,
+			owners:  map[string]map[string]int{},
+		},
+		policies:     mc,
+		weighted:     map[string]map[string][]config.WeightedService{},
+		failovers:    map[string]map[string]string{},
+		certificates: map[string]*tlstypes.CertAndStores{},
+	}
+	for i, src := range sources {
+		if src.Config == nil {
 			continue
 		}
-		mergeHTTP(merged, cfg)
-		mergeTCP(merged, cfg)
-		mergeUDP(merged, cfg)
-		mergeTLS(merged, cfg)
+		m.index = i
+		m.detachFailoverChildren(src.Config.HTTP)
+		m.mergeHTTP(src.Config.HTTP)
+		m.mergeTCP(src.Config.TCP)
+		m.mergeUDP(src.Config.UDP)
+		m.mergeTLS(src.Config.TLS)
+	}
+	m.buildFailovers()
+	return &m.MergeResult
+}
+
+// merger holds the state of a Merge while sources are merged in order.
+type merger struct {
+	MergeResult
+	policies *config.MergeConfig
+	// index is the index of the source being merged.
+	index int
+	// weighted holds, per kind, the children of the services built by
+	// MergeWeighted.
+	weighted map[string]map[string][]config.WeightedService
+	// failovers maps a failover service and provider config name to the
+	// provider's renamed copy of the service.
+	failovers map[string]map[string]string
+	// certificates indexes the merged TLS certificates by cert and key.
+	certificates map[string]*tlstypes.CertAndStores
+}
+
+func (m *merger) sourceName(i int) string {
+	if p := m.sources[i].Provider; p != nil && p.Name != "" {
+		return p.Name
 	}
-	return merged
+	return fmt.Sprintf("#%d", i)
 }
 
-func mergeHTTP(dst, src *dynamic.Configuration) {
-	if src.HTTP == nil {
+func (m *merger) mergeHTTP(src *dynamic.HTTPConfiguration) {
+	if src == nil {
 		return
 	}
-	for k, v := range src.HTTP.Routers {
-		dst.HTTP.Routers[k] = v
+	dst := m.Config.HTTP
+	overrides.RenameHTTPResources(src,
+		renames(m, "http service", dst.Services, src.Services, m.policies.ServicesPolicy()),
+		renames(m, "http middleware", dst.Middlewares, src.Middlewares, m.policies.MiddlewaresPolicy()),
+		renames(m, "http serversTransport", dst.ServersTransports, src.ServersTransports, m.policies.ServersTransportsPolicy()),
+	)
+	src.Routers = renameRouters(m, "http router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "http router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "http service", dst.Services, src.Services, m.policies.ServicesPolicy())
+	mergeMap(m, "http middleware", dst.Middlewares, src.Middlewares, m.policies.MiddlewaresPolicy())
+	mergeMap(m, "http serversTransport", dst.ServersTransports, src.ServersTransports, m.policies.ServersTransportsPolicy())
+	mergeMap(m, "http model", dst.Models, src.Models, m.policies.ModelsPolicy())
+}
+
+func (m *merger) mergeTCP(src *dynamic.TCPConfiguration) {
+	if src == nil {
+		return
 	}
-	for k, v := range src.HTTP.Services {
-		dst.HTTP.Services[k] = v
+	dst := m.Config.TCP
+	overrides.RenameTCPResources(src,
+		renames(m, "tcp service", dst.Services, src.Services, m.policies.ServicesPolicy()),
+		renames(m, "tcp middleware", dst.Middlewares, src.Middlewares, m.policies.MiddlewaresPolicy()),
+	)
+	src.Routers = renameRouters(m, "tcp router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "tcp router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "tcp service", dst.Services, src.Services, m.policies.ServicesPolicy())
+	mergeMap(m, "tcp middleware", dst.Middlewares, src.Middlewares, m.policies.MiddlewaresPolicy())
+}
+
+func (m *merger) mergeUDP(src *dynamic.UDPConfiguration) {
+	if src == nil {
+		return
 	}
-	for k, v := range src.HTTP.Middlewares {
-		dst.HTTP.Middlewares[k] = v
+	dst := m.Config.UDP
+	overrides.RenameUDPResources(src,
+		renames(m, "udp service", dst.Services, src.Services, m.policies.ServicesPolicy()),
+	)
+	src.Routers = renameRouters(m, "udp router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "udp router", dst.Routers, src.Routers, m.policies.RoutersPolicy())
+	mergeMap(m, "udp service", dst.Services, src.Services, m.policies.ServicesPolicy())
+}
+
+// detachFailoverChildren renames the services of the current source that are
+// part of a failover to "<service>-<provider name>". References are kept, so
+// the source's routers use the failover service.
+func (m *merger) detachFailoverChildren(src *dynamic.HTTPConfiguration) {
+	provider := m.sources[m.index].Provider
+	if src == nil || provider == nil || m.policies == nil {
+		return
 	}
-	for k, v := range src.HTTP.ServersTransports {
-		dst.HTTP.ServersTransports[k] = v
+	for _, f := range m.policies.Failovers {
+		svc, ok := src.Services[f.Service]
+		if !ok || !contains(f.Providers, provider.Name) {
+			continue
+		}
+		child := freeName(f.Service+"-"+suffix(provider.Name), m.Config.HTTP.Services, src.Services, nil)
+		delete(src.Services, f.Service)
+		src.Services[child] = svc
+		if m.failovers[f.Service] == nil {
+			m.failovers[f.Service] = map[string]string{}
+		}
+		m.failovers[f.Service][provider.Name] = child
 	}
 }
 
-func mergeTCP(dst, src *dynamic.Configuration) {
-	if src.TCP == nil {
+// buildFailovers adds the failover services over the detached children, in
+// provider priority order. A service defined by a provider not part of the
+// failover is replaced and reported as a conflict. Primaries without a health
+// check are reported as warnings: Traefik only fails over on health checks.
+func (m *merger) buildFailovers() {
+	if m.policies == nil {
 		return
 	}
-	for k, v := range src.TCP.Routers {
-		dst.TCP.Routers[k] = v
+	dst := m.Config.HTTP.Services
+	for _, f := range m.policies.Failovers {
+		var children []string
+		for _, p := range f.Providers {
+			if child, ok := m.failovers[f.Service][p]; ok {
+				children = append(children, child)
+			}
+		}
+		if len(children) == 0 {
+			continue
+		}
+		if _, ok := dst[f.Service]; ok {
+			m.Conflicts = append(m.Conflicts, Conflict{
+				Kind: "http service", Name: f.Service, First: m.sourceName(m.owners["http service"][f.Service]),
+				Second: strings.Join(f.Providers, ","), Policy: "failover",
+			})
+		}
+		if len(children) == 1 {
+			dst[f.Service] = dst[children[0]]
+			continue
+		}
+		names := []string{f.Service}
+		for k := 1; k < len(children)-1; k++ {
+			names = append(names, freeName(fmt.Sprintf("%s-failover-%d", f.Service, k), dst, nil, nil))
+		}
+		for k, name := range names {
+			if svc := dst[children[k]]; svc == nil || svc.LoadBalancer == nil || svc.LoadBalancer.HealthCheck == nil {
+				m.Warnings = append(m.Warnings, fmt.Errorf("http service %q: failover primary %q has no loadBalancer.healthCheck, so it never fails over", f.Service, children[k]))
+			}
+			fallback := children[k+1]
+			if k+1 < len(names) {
+				fallback = names[k+1]
+			}
+			dst[name] = &dynamic.Service{Failover: &dynamic.Failover{Service: children[k], Fallback: fallback}}
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// renames returns, under MergeRename, the new names of the src resources
+// conflicting with dst, and records the conflicts.
+func renames[T any](m *merger, kind string, dst, src map[string]*T, policy string) map[string]string {
+	if policy != config.MergeRename {
+		return nil
 	}
-	for k, v := range src.TCP.Services {
-		dst.TCP.Services[k] = v
+	out := map[string]string{}
+	for _, name := range sortedKeys(src) {
+		existing, ok := dst[name]
+		if !ok || reflect.DeepEqual(existing, src[name]) {
+			continue
+		}
+		newName := freeName(name+"-"+suffix(m.sourceName(m.index)), dst, src, out)
+		out[name] = newName
+		m.Conflicts = append(m.Conflicts, Conflict{
+			Kind: kind, Name: name, First: m.sourceName(m.owners[kind][name]), Second: m.sourceName(m.index),
+			Policy: policy, Renamed: newName,
+		})
+	}
+	return out
+}
+
+// renameRouters returns src with the routers conflicting with dst renamed
+// under MergeRename. Routers are not referenced, so only keys change.
+func renameRouters[T any](m *merger, kind string, dst, src map[string]*T, policy string) map[string]*T {
+	mapping := renames(m, kind, dst, src, policy)
+	if len(mapping) == 0 {
+		return src
 	}
-	for k, v := range src.TCP.Middlewares {
-		dst.TCP.Middlewares[k] = v
+	out := make(map[string]*T, len(src))
+	for name, v := range src {
+		if newName, ok := mapping[name]; ok {
+			name = newName
+		}
+		out[name] = v
 	}
+	return out
 }
 
-func mergeUDP(dst, src *dynamic.Configuration) {
-	if src.UDP == nil {
-		return
+// freeName returns base, or base with a number if it is already used by dst,
+// src or planned renames.
+func freeName[T any](base string, dst, src map[string]*T, planned map[string]string) string {
+	taken := func(candidate string) bool {
+		_, inDst := dst[candidate]
+		_, inSrc := src[candidate]
+		if inDst || inSrc {
+			return true
+		}
+		for _, p := range planned {
+			if p == candidate {
+				return true
+			}
+		}
+		return false
+	}
+	if !taken(base) {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// mergeMap merges src into dst according to policy, recording conflicts and
+// the source each kept resource comes from.
+func mergeMap[T any](m *merger, kind string, dst, src map[string]*T, policy string) {
+	owners := m.owners[kind]
+	if owners == nil {
+		owners = map[string]int{}
+		m.owners[kind] = owners
+	}
+	for _, name := range sortedKeys(src) {
+		v := src[name]
+		existing, ok := dst[name]
+		if !ok || reflect.DeepEqual(existing, v) {
+			dst[name] = v
+			owners[name] = m.index
+			continue
+		}
+		c := Conflict{Kind: kind, Name: name, First: m.sourceName(owners[name]), Second: m.sourceName(m.index), Policy: policy}
+		switch policy {
+		case config.MergeFirstWins, config.MergeError:
+		case config.MergeDeep:
+			merged, err := overrides.DeepMerge(existing, v)
+			if err != nil {
+				c.Err = err
+				break
+			}
+			dst[name] = merged
+			owners[name] = m.index
+		case config.MergeUnion:
+			merged, err := overrides.UnionServers(existing, v)
+			if err != nil {
+				c.Err = err
+				break
+			}
+			dst[name] = merged
+			owners[name] = m.index
+		case config.MergeWeighted:
+			c.Err = mergeWeighted(m, kind, name, dst, src)
+		default:
+			dst[name] = v
+			owners[name] = m.index
+		}
+		m.Conflicts = append(m.Conflicts, c)
+	}
+}
+
+// mergeWeighted replaces the service name of dst with a weighted service over
+// one child per provider defining it, adding the child of the current source.
+func mergeWeighted[T any](m *merger, kind, name string, dst, src map[string]*T) error {
+	owners := m.owners[kind]
+	weighted := m.weighted[kind]
+	if weighted == nil {
+		weighted = map[string][]config.WeightedService{}
+		m.weighted[kind] = weighted
+	}
+	child := func(i int, planned map[string]string) config.WeightedService {
+		weight := m.policies.Weight(m.sourceName(i))
+		return config.WeightedService{
+			Name:   freeName(name+"-"+suffix(m.sourceName(i)), dst, src, planned),
+			Weight: &weight,
+		}
+	}
+
+	children := weighted[name]
+	planned := map[string]string{}
+	var first *config.WeightedService
+	if children == nil {
+		c := child(owners[name], planned)
+		planned[name] = c.Name
+		first = &c
+		children = []config.WeightedService{c}
 	}
-	for k, v := range src.UDP.Routers {
-		dst.UDP.Routers[k] = v
+	next := child(m.index, planned)
+	children = append(children, next)
+
+	svc, err := overrides.WeightedService[T](children)
+	if err != nil {
+		return err
 	}
-	for k, v := range src.UDP.Services {
-		dst.UDP.Services[k] = v
+	if first != nil {
+		dst[first.Name] = dst[name]
+		owners[first.Name] = owners[name]
 	}
+	dst[next.Name] = src[name]
+	owners[next.Name] = m.index
+	dst[name] = svc
+	owners[name] = m.index
+	weighted[name] = children
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suffix returns s reduced to characters safe in resource names.
+func suffix(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, s)
 }
 
-func mergeTLS(dst, src *dynamic.Configuration) {
-	if src.TLS == nil {
+// mergeTLS merges the TLS section of a source. Certificates with the same
+// cert and key are kept once with their stores combined; options and stores
+// are merged by name with the TLS policies.
+func (m *merger) mergeTLS(src *dynamic.TLSConfiguration) {
+	if src == nil {
 		return
 	}
-	dst.TLS.Certificates = append(dst.TLS.Certificates, src.TLS.Certificates...)
-	for k, v := range src.TLS.Options {
-		dst.TLS.Options[k] = v
+	dst := m.Config.TLS
+	for _, c := range src.Certificates {
+		if c == nil {
+			continue
+		}
+		key := c.CertFile + "\x00" + c.KeyFile
+		if existing, ok := m.certificates[key]; ok {
+			existing.Stores = unionStores(existing.Stores, c.Stores)
+			continue
+		}
+		cert := &tlstypes.CertAndStores{Certificate: c.Certificate, Stores: append([]string(nil), c.Stores...)}
+		m.certificates[key] = cert
+		dst.Certificates = append(dst.Certificates, cert)
 	}
-	for k, v := range src.TLS.Stores {
-		dst.TLS.Stores[k] = v
+	mergeTLSMap(m, "tls options", dst.Options, src.Options, m.policies.TLSOptionsPolicy())
+	mergeTLSMap(m, "tls store", dst.Stores, src.Stores, m.policies.TLSStoresPolicy())
+}
+
+// unionStores returns the stores of a and b without duplicates. A certificate
+// without stores belongs to the default store, which is kept explicit when
+// the other certificate lists stores.
+func unionStores(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	if len(a) == 0 {
+		a = []string{tlsDefault}
+	}
+	if len(b) == 0 {
+		b = []string{tlsDefault}
+	}
+	out := make([]string, 0, len(a)+len(b))
+	seen := map[string]bool{}
+	for _, s := range append(append([]string(nil), a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// tlsDefault is the name of the TLS options and store used when none is set.
+const tlsDefault = "default"
+
+// mergeTLSMap merges TLS options or stores of src into dst like mergeMap,
+// resolving conflicts on "default" with the TLS defaults policy.
+func mergeTLSMap[T any](m *merger, kind string, dst, src map[string]T, policy string) {
+	owners := m.owners[kind]
+	if owners == nil {
+		owners = map[string]int{}
+		m.owners[kind] = owners
+	}
+	for _, name := range sortedKeys(src) {
+		v := src[name]
+		existing, ok := dst[name]
+		if !ok || reflect.DeepEqual(existing, v) {
+			dst[name] = v
+			owners[name] = m.index
+			continue
+		}
+		p := policy
+		if name == tlsDefault {
+			p = m.policies.TLSDefaultsPolicy()
+		}
+		c := Conflict{Kind: kind, Name: name, First: m.sourceName(owners[name]), Second: m.sourceName(m.index), Policy: p}
+		switch p {
+		case config.MergeFirstWins, config.MergeError:
+		case config.MergeDeep:
+			merged, err := overrides.DeepMerge(&existing, &v)
+			if err != nil {
+				c.Err = err
+				break
+			}
+			dst[name] = *merged
+			owners[name] = m.index
+		default:
+			dst[name] = v
+			owners[name] = m.index
+		}
+		m.Conflicts = append(m.Conflicts, c)
 	}
 }
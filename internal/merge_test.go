@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/traefik/genconf/dynamic"
 	tlstypes "github.com/traefik/genconf/dynamic/tls"
+	"github.com/zalbiraw/traefikprovider/config"
 )
 
 func assertHTTPInitialized(t *testing.T, http *dynamic.HTTPConfiguration) {
@@ -329,3 +332,285 @@ func TestMergeConfigurationsTLSCertificateAppend(t *testing.T) {
 		t.Error("Expected second certificate to be appended")
 	}
 }
+
+func conflictSources() []Source {
+	return []Source{
+		{Provider: &config.ProviderConfig{Name: "east"}, Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+			Routers:  map[string]*dynamic.Router{"api": {Rule: "Host(`east`)", Service: "api"}},
+			Services: map[string]*dynamic.Service{"api": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://east"}}}}},
+			Middlewares: map[string]*dynamic.Middleware{
+				"headers": {Headers: &dynamic.Headers{CustomRequestHeaders: map[string]string{"X-Region": "east"}}},
+			},
+		}}},
+		{Provider: &config.ProviderConfig{Name: "west coast"}, Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+			Routers:  map[string]*dynamic.Router{"api": {Rule: "Host(`west`)", Service: "api", Middlewares: []string{"headers"}}},
+			Services: map[string]*dynamic.Service{"api": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://west"}}}}},
+			Middlewares: map[string]*dynamic.Middleware{
+				"headers": {Headers: &dynamic.Headers{CustomRequestHeaders: map[string]string{"X-Region": "east"}}},
+			},
+		}}},
+	}
+}
+
+func TestMerge_LastAndFirstWins(t *testing.T) {
+	result := Merge(conflictSources(), nil)
+	if got := result.Config.HTTP.Routers["api"].Rule; got != "Host(`west`)" {
+		t.Errorf("last-wins rule=%q", got)
+	}
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("expected router and service conflicts, got %v", result.Conflicts)
+	}
+	c := result.Conflicts[0]
+	if c.Kind != "http router" || c.Name != "api" || c.First != "east" || c.Second != "west coast" || c.Policy != config.MergeLastWins {
+		t.Errorf("unexpected conflict: %+v", c)
+	}
+	if result.Failed() {
+		t.Error("last-wins must not fail")
+	}
+	if got := result.Origin("http", "api"); got == nil || got.Name != "west coast" {
+		t.Errorf("origin=%+v", got)
+	}
+
+	result = Merge(conflictSources(), &config.MergeConfig{Routers: config.MergeFirstWins, Services: config.MergeFirstWins})
+	if got := result.Config.HTTP.Routers["api"].Rule; got != "Host(`east`)" {
+		t.Errorf("first-wins rule=%q", got)
+	}
+	if got := result.Origin("http", "api"); got == nil || got.Name != "east" {
+		t.Errorf("origin=%+v", got)
+	}
+}
+
+func TestMerge_Error(t *testing.T) {
+	result := Merge(conflictSources(), &config.MergeConfig{Services: config.MergeError})
+	if !result.Failed() {
+		t.Error("expected merge to fail")
+	}
+}
+
+func TestMerge_Rename(t *testing.T) {
+	sources := conflictSources()
+	sources[1].Config.HTTP.Services["api-west-coast"] = &dynamic.Service{}
+	result := Merge(sources, &config.MergeConfig{Routers: config.MergeRename, Services: config.MergeRename})
+	h := result.Config.HTTP
+
+	if h.Routers["api"].Service != "api" {
+		t.Errorf("first router changed: %+v", h.Routers["api"])
+	}
+	r := h.Routers["api-west-coast"]
+	if r == nil || r.Service != "api-west-coast-2" {
+		t.Fatalf("unexpected renamed router: %+v", h.Routers)
+	}
+	if got := h.Services["api-west-coast-2"].LoadBalancer.Servers[0].URL; got != "http://west" {
+		t.Errorf("renamed service url=%q", got)
+	}
+	if len(result.Conflicts) != 2 || result.Conflicts[0].Renamed != "api-west-coast-2" {
+		t.Errorf("conflicts=%v", result.Conflicts)
+	}
+	if got := result.Origin("http", "api-west-coast"); got == nil || got.Name != "west coast" {
+		t.Errorf("origin=%+v", got)
+	}
+}
+
+func TestMerge_DeepMerge(t *testing.T) {
+	sources := conflictSources()
+	sources[1].Config.HTTP.Services["api"].LoadBalancer.HealthCheck = &dynamic.ServerHealthCheck{Path: "/health"}
+	sources[0].Config.HTTP.Services["api"].LoadBalancer.ServersTransport = "tunnel"
+	result := Merge(sources, &config.MergeConfig{Services: config.MergeDeep})
+
+	lb := result.Config.HTTP.Services["api"].LoadBalancer
+	if lb.ServersTransport != "tunnel" || lb.HealthCheck == nil || lb.Servers[0].URL != "http://west" {
+		t.Errorf("unexpected merged load balancer: %+v", lb)
+	}
+	if result.Conflicts[1].Policy != config.MergeDeep || result.Conflicts[1].Err != nil {
+		t.Errorf("conflicts=%v", result.Conflicts)
+	}
+}
+
+func regionSources(regions ...string) []Source {
+	sources := make([]Source, 0, len(regions))
+	for _, region := range regions {
+		sources = append(sources, Source{
+			Provider: &config.ProviderConfig{Name: region},
+			Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{"checkout-" + region: {Service: "checkout"}},
+				Services: map[string]*dynamic.Service{"checkout": {LoadBalancer: &dynamic.ServersLoadBalancer{
+					Servers: []dynamic.Server{{URL: "http://shared"}, {URL: "http://" + region}},
+				}}},
+			}},
+		})
+	}
+	return sources
+}
+
+func TestMerge_Union(t *testing.T) {
+	result := Merge(regionSources("east", "west", "north"), &config.MergeConfig{Services: config.MergeUnion})
+	want := []dynamic.Server{{URL: "http://shared"}, {URL: "http://east"}, {URL: "http://west"}, {URL: "http://north"}}
+	if got := result.Config.HTTP.Services["checkout"].LoadBalancer.Servers; !reflect.DeepEqual(got, want) {
+		t.Errorf("servers=%v", got)
+	}
+	if len(result.Conflicts) != 2 {
+		t.Errorf("conflicts=%v", result.Conflicts)
+	}
+}
+
+func TestMerge_Weighted(t *testing.T) {
+	result := Merge(regionSources("east", "west", "north"), &config.MergeConfig{Services: config.MergeWeighted, Weights: map[string]int{"east": 3}})
+	h := result.Config.HTTP
+
+	svc := h.Services["checkout"]
+	if svc.Weighted == nil || len(svc.Weighted.Services) != 3 {
+		t.Fatalf("unexpected service: %+v", svc)
+	}
+	for i, want := range []struct {
+		name   string
+		weight int
+	}{{"checkout-east", 3}, {"checkout-west", 1}, {"checkout-north", 1}} {
+		got := svc.Weighted.Services[i]
+		if got.Name != want.name || got.Weight == nil || *got.Weight != want.weight {
+			t.Errorf("child %d=%+v want %+v", i, got, want)
+		}
+		if child := h.Services[want.name]; child == nil || child.LoadBalancer == nil {
+			t.Errorf("child %q missing", want.name)
+		}
+	}
+	if got := h.Services["checkout-west"].LoadBalancer.Servers[1].URL; got != "http://west" {
+		t.Errorf("west child url=%q", got)
+	}
+}
+
+func TestMerge_Failovers(t *testing.T) {
+	sources := regionSources("east", "west", "north", "south")
+	sources[3].Config.HTTP.Services["checkout"] = &dynamic.Service{}
+	result := Merge(sources, &config.MergeConfig{Failovers: []config.FailoverService{
+		{Service: "checkout", Providers: []string{"west", "east", "north"}},
+	}})
+	h := result.Config.HTTP
+
+	if got := h.Services["checkout"].Failover; got == nil || got.Service != "checkout-west" || got.Fallback != "checkout-failover-1" {
+		t.Fatalf("unexpected failover: %+v", h.Services["checkout"])
+	}
+	if got := h.Services["checkout-failover-1"].Failover; got == nil || got.Service != "checkout-east" || got.Fallback != "checkout-north" {
+		t.Errorf("unexpected nested failover: %+v", got)
+	}
+	for _, region := range []string{"east", "west", "north"} {
+		if got := h.Services["checkout-"+region].LoadBalancer.Servers[1].URL; got != "http://"+region {
+			t.Errorf("child %s url=%q", region, got)
+		}
+	}
+	if got := h.Routers["checkout-east"].Service; got != "checkout" {
+		t.Errorf("router service=%q", got)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].First != "south" {
+		t.Errorf("expected the south service to be reported, got %v", result.Conflicts)
+	}
+}
+
+func TestMerge_FailoverHealthCheckWarning(t *testing.T) {
+	sources := regionSources("east", "west", "north")
+	sources[0].Config.HTTP.Services["checkout"].LoadBalancer.HealthCheck = &dynamic.ServerHealthCheck{Path: "/health"}
+	result := Merge(sources, &config.MergeConfig{Failovers: []config.FailoverService{
+		{Service: "checkout", Providers: []string{"east", "west", "north"}},
+	}})
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Error(), `"checkout-west"`) {
+		t.Errorf("expected a warning for the west primary only, got %v", result.Warnings)
+	}
+	if len(result.Conflicts) != 0 || result.Failed() {
+		t.Errorf("warnings must not be conflicts: %v", result.Conflicts)
+	}
+}
+
+func TestMerge_FailoverSingleProvider(t *testing.T) {
+	result := Merge(regionSources("east"), &config.MergeConfig{Failovers: []config.FailoverService{
+		{Service: "checkout", Providers: []string{"east", "west"}},
+	}})
+	h := result.Config.HTTP
+	if h.Services["checkout"] == nil || h.Services["checkout"] != h.Services["checkout-east"] {
+		t.Errorf("expected checkout to use the only available copy: %+v", h.Services)
+	}
+}
+
+func tlsSources() []Source {
+	cert := func(stores ...string) []*tlstypes.CertAndStores {
+		return []*tlstypes.CertAndStores{{Certificate: tlstypes.Certificate{CertFile: "/certs/a.pem", KeyFile: "/certs/a.key"}, Stores: stores}}
+	}
+	return []Source{
+		{Provider: &config.ProviderConfig{Name: "east"}, Config: &dynamic.Configuration{TLS: &dynamic.TLSConfiguration{
+			Certificates: cert(),
+			Options:      map[string]tlstypes.Options{"default": {MinVersion: "VersionTLS12"}, "strict": {MinVersion: "VersionTLS13"}},
+			Stores:       map[string]tlstypes.Store{"default": {DefaultCertificate: &tlstypes.Certificate{CertFile: "/certs/east.pem"}}},
+		}}},
+		{Provider: &config.ProviderConfig{Name: "west"}, Config: &dynamic.Configuration{TLS: &dynamic.TLSConfiguration{
+			Certificates: cert("internal"),
+			Options:      map[string]tlstypes.Options{"default": {MinVersion: "VersionTLS11"}, "strict": {SniStrict: true}},
+			Stores:       map[string]tlstypes.Store{"default": {DefaultCertificate: &tlstypes.Certificate{CertFile: "/certs/west.pem"}}},
+		}}},
+	}
+}
+
+func TestMerge_TLSCertificateDedupe(t *testing.T) {
+	result := Merge(tlsSources(), nil)
+	certs := result.Config.TLS.Certificates
+	if len(certs) != 1 {
+		t.Fatalf("expected 1 certificate, got %d", len(certs))
+	}
+	if !reflect.DeepEqual(certs[0].Stores, []string{"default", "internal"}) {
+		t.Errorf("stores=%v", certs[0].Stores)
+	}
+	if got := MergeConfigurations(tlsSources()[0].Config, tlsSources()[0].Config).TLS.Certificates; len(got) != 1 || got[0].Stores != nil {
+		t.Errorf("expected a re-fetched certificate to be kept once: %+v", got)
+	}
+}
+
+func TestMerge_TLSPolicies(t *testing.T) {
+	result := Merge(tlsSources(), nil)
+	tls := result.Config.TLS
+	if tls.Options["default"].MinVersion != "VersionTLS12" || tls.Stores["default"].DefaultCertificate.CertFile != "/certs/east.pem" {
+		t.Errorf("expected the first default options and store to be kept: %+v", tls)
+	}
+	if tls.Options["strict"].MinVersion != "" || !tls.Options["strict"].SniStrict {
+		t.Errorf("expected the last strict options to win: %+v", tls.Options["strict"])
+	}
+	if len(result.Conflicts) != 3 || result.Failed() {
+		t.Errorf("unexpected conflicts: %v", result.Conflicts)
+	}
+
+	result = Merge(tlsSources(), &config.MergeConfig{TLSOptions: config.MergeDeep, TLSDefaults: config.MergeLastWins})
+	tls = result.Config.TLS
+	if strict := tls.Options["strict"]; strict.MinVersion != "VersionTLS13" || !strict.SniStrict {
+		t.Errorf("expected deep-merged strict options: %+v", strict)
+	}
+	if tls.Stores["default"].DefaultCertificate.CertFile != "/certs/west.pem" {
+		t.Error("expected the last default store to win")
+	}
+
+	if !Merge(tlsSources(), &config.MergeConfig{TLSDefaults: config.MergeError}).Failed() {
+		t.Error("expected conflicting defaults to fail the merge")
+	}
+}
+
+func TestMerge_Models(t *testing.T) {
+	sources := func() []Source {
+		return []Source{
+			{Provider: &config.ProviderConfig{Name: "east"}, Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+				Models: map[string]*dynamic.Model{"websecure": {Middlewares: []string{"headers"}}, "web": {}},
+			}}},
+			{Provider: &config.ProviderConfig{Name: "west"}, Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+				Models: map[string]*dynamic.Model{"websecure": {Middlewares: []string{"auth"}}, "web": {}},
+			}}},
+		}
+	}
+	result := Merge(sources(), nil)
+	models := result.Config.HTTP.Models
+	if len(models) != 2 || !reflect.DeepEqual(models["websecure"].Middlewares, []string{"auth"}) {
+		t.Errorf("expected the last websecure model to win: %+v", models)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Kind != "http model" {
+		t.Errorf("unexpected conflicts: %v", result.Conflicts)
+	}
+
+	result = Merge(sources(), &config.MergeConfig{Models: config.MergeFirstWins})
+	if got := result.Config.HTTP.Models["websecure"].Middlewares; !reflect.DeepEqual(got, []string{"headers"}) {
+		t.Errorf("expected the first websecure model to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package overrides
+
+import (
+	"fmt"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/jsonpatch"
+)
+
+// DeepMerge returns overlay merged into base as a JSON merge patch: objects
+// are merged recursively, other values from overlay replace those of base.
+// Neither argument is modified.
+func DeepMerge[T any](base, overlay *T) (*T, error) {
+	baseDoc, err := toJSONValue(base)
+	if err != nil {
+		return nil, err
+	}
+	overlayDoc, err := toJSONValue(overlay)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResource[T](jsonpatch.MergePatch(baseDoc, overlayDoc))
+}
+
+// UnionServers combines two HTTP, TCP or UDP load balancer services into one
+// load balancer serving the servers of both, first's servers first. A server
+// of second whose URL or address is already served is dropped, so first's
+// entry, with its weight, wins. Other settings, such as the health check or servers
+// transport, are taken from first and completed from second.
+func UnionServers[T any](first, second *T) (*T, error) {
+	firstDoc, err := loadBalancerDoc(first)
+	if err != nil {
+		return nil, err
+	}
+	secondDoc, err := loadBalancerDoc(second)
+	if err != nil {
+		return nil, err
+	}
+	servers, _ := firstDoc["loadBalancer"].(map[string]interface{})["servers"].([]interface{})
+	extra, _ := secondDoc["loadBalancer"].(map[string]interface{})["servers"].([]interface{})
+	seen := make(map[string]bool, len(servers))
+	for _, srv := range servers {
+		seen[serverKey(srv)] = true
+	}
+	for _, srv := range extra {
+		if key := serverKey(srv); !seen[key] {
+			seen[key] = true
+			servers = append(servers, srv)
+		}
+	}
+	merged := jsonpatch.MergePatch(secondDoc, firstDoc).(map[string]interface{})
+	if len(servers) > 0 {
+		merged["loadBalancer"].(map[string]interface{})["servers"] = servers
+	}
+	return decodeResource[T](merged)
+}
+
+// loadBalancerDoc returns the JSON form of a service that only has a load balancer.
+func loadBalancerDoc(v interface{}) (map[string]interface{}, error) {
+	doc, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := doc.(map[string]interface{})
+	if _, ok := m["loadBalancer"].(map[string]interface{}); !ok || len(m) != 1 {
+		return nil, fmt.Errorf("only load balancer services can be combined")
+	}
+	return m, nil
+}
+
+// serverKey identifies a server by its URL (HTTP) or address (TCP and UDP).
+func serverKey(srv interface{}) string {
+	m, _ := srv.(map[string]interface{})
+	if url, ok := m["url"].(string); ok {
+		return url
+	}
+	address, _ := m["address"].(string)
+	return address
+}
+
+// WeightedService returns an HTTP, TCP or UDP weighted service over children.
+func WeightedService[T any](children []config.WeightedService) (*T, error) {
+	doc, err := toJSONValue(map[string]interface{}{
+		"weighted": map[string]interface{}{"services": children},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeResource[T](doc)
+}
+
+// RenameHTTPResources renames HTTP services, middlewares and servers
+// transports from old to new names and rewrites the references to them.
+func RenameHTTPResources(cfg *dynamic.HTTPConfiguration, services, middlewares, transports map[string]string) {
+	if cfg == nil {
+		return
+	}
+	moveKeys(cfg.Services, services)
+	moveKeys(cfg.Middlewares, middlewares)
+	moveKeys(cfg.ServersTransports, transports)
+	walkHTTPRefs(cfg, renamedRefs(services, middlewares, transports))
+}
+
+// RenameTCPResources renames TCP services and middlewares and rewrites the
+// references to them.
+func RenameTCPResources(cfg *dynamic.TCPConfiguration, services, middlewares map[string]string) {
+	if cfg == nil {
+		return
+	}
+	moveKeys(cfg.Services, services)
+	moveKeys(cfg.Middlewares, middlewares)
+	walkTCPRefs(cfg, renamedRefs(services, middlewares, nil))
+}
+
+// RenameUDPResources renames UDP services and rewrites the references to them.
+func RenameUDPResources(cfg *dynamic.UDPConfiguration, services map[string]string) {
+	if cfg == nil {
+		return
+	}
+	moveKeys(cfg.Services, services)
+	walkUDPRefs(cfg, renamedRefs(services, nil, nil))
+}
+
+// moveKeys moves the entries of m from old to new names. New names must not
+// be in use.
+func moveKeys[T any](m map[string]*T, mapping map[string]string) {
+	moved := make(map[string]*T, len(mapping))
+	for oldName, newName := range mapping {
+		if v, ok := m[oldName]; ok {
+			moved[newName] = v
+			delete(m, oldName)
+		}
+	}
+	for name, v := range moved {
+		m[name] = v
+	}
+}
@@ -0,0 +1,163 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestDeepMerge(t *testing.T) {
+	passHost := false
+	base := &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers:     []dynamic.Server{{URL: "http://a"}},
+		HealthCheck: &dynamic.ServerHealthCheck{Path: "/health"},
+	}}
+	overlay := &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers:        []dynamic.Server{{URL: "http://b"}},
+		PassHostHeader: &passHost,
+	}}
+
+	got, err := DeepMerge(base, overlay)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lb := got.LoadBalancer
+	if !reflect.DeepEqual(lb.Servers, []dynamic.Server{{URL: "http://b"}}) {
+		t.Errorf("servers=%v", lb.Servers)
+	}
+	if lb.HealthCheck == nil || lb.HealthCheck.Path != "/health" || lb.PassHostHeader == nil || *lb.PassHostHeader {
+		t.Errorf("unexpected load balancer: %+v", lb)
+	}
+	if base.LoadBalancer.PassHostHeader != nil || overlay.LoadBalancer.HealthCheck != nil {
+		t.Error("inputs were modified")
+	}
+}
+
+func TestRenameResources(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{"api": {Service: "api", Middlewares: []string{"secured"}}},
+			Services: map[string]*dynamic.Service{
+				"api":    {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "tunnel"}},
+				"api-v2": {},
+				"canary": {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api"}, {Name: "api-v2"}}}},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"secured": {Chain: &dynamic.Chain{Middlewares: []string{"errors", "auth@file"}}},
+				"errors":  {},
+			},
+			ServersTransports: map[string]*dynamic.ServersTransport{"tunnel": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  map[string]*dynamic.TCPRouter{"db": {Service: "db"}},
+			Services: map[string]*dynamic.TCPService{"db": {}},
+		},
+		UDP: &dynamic.UDPConfiguration{Services: map[string]*dynamic.UDPService{
+			"dns":     {},
+			"dns-wrr": {Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns"}}}},
+		}},
+	}
+	RenameHTTPResources(cfg.HTTP, map[string]string{"api": "api-east"}, map[string]string{"errors": "errors-east"}, map[string]string{"tunnel": "tunnel-east"})
+	RenameTCPResources(cfg.TCP, map[string]string{"db": "db-east"}, nil)
+	RenameUDPResources(cfg.UDP, map[string]string{"dns": "dns-east"})
+
+	h := cfg.HTTP
+	if _, ok := h.Services["api"]; ok {
+		t.Error("old service name still present")
+	}
+	if got := h.Routers["api"].Service; got != "api-east" {
+		t.Errorf("router service=%q", got)
+	}
+	if got := h.Services["api-east"].LoadBalancer.ServersTransport; got != "tunnel-east" {
+		t.Errorf("serversTransport=%q", got)
+	}
+	if got := h.Middlewares["secured"].Chain.Middlewares; !reflect.DeepEqual(got, []string{"errors-east", "auth@file"}) {
+		t.Errorf("chain=%v", got)
+	}
+	if got := h.Services["canary"].Weighted.Services[1].Name; got != "api-v2" {
+		t.Errorf("unrelated reference rewritten: %q", got)
+	}
+	if got := cfg.TCP.Routers["db"].Service; got != "db-east" {
+		t.Errorf("tcp router service=%q", got)
+	}
+	if got := cfg.UDP.Services["dns-wrr"].Weighted.Services[0].Name; got != "dns-east" {
+		t.Errorf("udp weighted=%q", got)
+	}
+}
+
+func TestUnionServers(t *testing.T) {
+	first := &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers:     []dynamic.Server{{URL: "http://a"}, {URL: "http://b"}},
+		HealthCheck: &dynamic.ServerHealthCheck{Path: "/health"},
+	}}
+	second := &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers:          []dynamic.Server{{URL: "http://b"}, {URL: "http://c"}},
+		HealthCheck:      &dynamic.ServerHealthCheck{Path: "/ready", Interval: "5s"},
+		ServersTransport: "tunnel",
+	}}
+
+	got, err := UnionServers(first, second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lb := got.LoadBalancer
+	if !reflect.DeepEqual(lb.Servers, []dynamic.Server{{URL: "http://a"}, {URL: "http://b"}, {URL: "http://c"}}) {
+		t.Errorf("servers=%v", lb.Servers)
+	}
+	if !reflect.DeepEqual(lb.HealthCheck, &dynamic.ServerHealthCheck{Path: "/health", Interval: "5s"}) || lb.ServersTransport != "tunnel" {
+		t.Errorf("unexpected settings: %+v", lb)
+	}
+
+	tcp, err := UnionServers(
+		&dynamic.TCPService{LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "a:5432"}}}},
+		&dynamic.TCPService{LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "a:5432"}, {Address: "b:5432"}}}},
+	)
+	if err != nil || len(tcp.LoadBalancer.Servers) != 2 {
+		t.Errorf("tcp union=%+v err=%v", tcp, err)
+	}
+
+	if _, err := UnionServers(first, &dynamic.Service{Weighted: &dynamic.WeightedRoundRobin{}}); err == nil {
+		t.Error("expected error for a weighted service")
+	}
+}
+
+func TestUnionServers_SameURLDifferentWeight(t *testing.T) {
+	// The vendored server type has no weight; Traefik's does, so the union
+	// must key servers on their URL rather than on the whole entry.
+	type server struct {
+		URL    string `json:"url,omitempty"`
+		Weight *int   `json:"weight,omitempty"`
+	}
+	type service struct {
+		LoadBalancer struct {
+			Servers []server `json:"servers,omitempty"`
+		} `json:"loadBalancer"`
+	}
+	one, two := 1, 2
+	first, second := &service{}, &service{}
+	first.LoadBalancer.Servers = []server{{URL: "http://a", Weight: &one}}
+	second.LoadBalancer.Servers = []server{{URL: "http://a", Weight: &two}, {URL: "http://b", Weight: &two}}
+
+	got, err := UnionServers(first, second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	servers := got.LoadBalancer.Servers
+	if len(servers) != 2 || servers[0].URL != "http://a" || *servers[0].Weight != 1 || servers[1].URL != "http://b" {
+		t.Errorf("servers=%+v", servers)
+	}
+}
+
+func TestWeightedService(t *testing.T) {
+	two := 2
+	got, err := WeightedService[dynamic.UDPService]([]config.WeightedService{{Name: "dns-east", Weight: &two}, {Name: "dns-west"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &dynamic.UDPService{Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns-east", Weight: &two}, {Name: "dns-west"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got.Weighted, want.Weighted)
+	}
+}
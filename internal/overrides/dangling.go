@@ -0,0 +1,131 @@
+package overrides
+
+import (
+	"fmt"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+// Router protocols passed to a RouterOrigin.
+const (
+	ProtocolHTTP = "http"
+	ProtocolTCP  = "tcp"
+	ProtocolUDP  = "udp"
+)
+
+// RouterOrigin returns the provider configuration a router of the merged
+// configuration came from, or nil when it is unknown.
+type RouterOrigin func(protocol, router string) *config.ProviderConfig
+
+// CheckReferences looks for router services and middlewares missing from cfg
+// and applies the dangling reference policy of each router's provider.
+// References to the provider's preserved providers are allowed. Routers of
+// unknown origin use the default policy. Missing model middlewares are only
+// reported. It returns an error per dangling reference and per router dropped
+// or changed.
+func CheckReferences(cfg *dynamic.Configuration, origin RouterOrigin) []error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []error
+	if h := cfg.HTTP; h != nil {
+		for _, name := range sortedKeys(h.Routers) {
+			r := h.Routers[name]
+			drop, mws, rerrs := checkRouter(ProtocolHTTP, name, r.Service, r.Middlewares, hasKey(h.Services), hasKey(h.Middlewares), origin)
+			errs = append(errs, rerrs...)
+			if drop {
+				delete(h.Routers, name)
+				continue
+			}
+			r.Middlewares = mws
+		}
+		for _, name := range sortedKeys(h.Models) {
+			for _, mw := range h.Models[name].Middlewares {
+				if !config.IsPreserved(mw, config.DefaultPreserveProviders) && !hasKey(h.Middlewares)(mw) {
+					errs = append(errs, fmt.Errorf("http model %q: middleware %q not found", name, mw))
+				}
+			}
+		}
+	}
+	if t := cfg.TCP; t != nil {
+		for _, name := range sortedKeys(t.Routers) {
+			r := t.Routers[name]
+			drop, mws, rerrs := checkRouter(ProtocolTCP, name, r.Service, r.Middlewares, hasKey(t.Services), hasKey(t.Middlewares), origin)
+			errs = append(errs, rerrs...)
+			if drop {
+				delete(t.Routers, name)
+				continue
+			}
+			r.Middlewares = mws
+		}
+	}
+	if u := cfg.UDP; u != nil {
+		for _, name := range sortedKeys(u.Routers) {
+			r := u.Routers[name]
+			drop, _, rerrs := checkRouter(ProtocolUDP, name, r.Service, nil, hasKey(u.Services), nil, origin)
+			errs = append(errs, rerrs...)
+			if drop {
+				delete(u.Routers, name)
+			}
+		}
+	}
+	return errs
+}
+
+// checkRouter reports the dangling references of a router and returns
+// whether to drop it and the middlewares to keep.
+func checkRouter(protocol, name, service string, middlewares []string, hasService, hasMiddleware func(string) bool, origin RouterOrigin) (bool, []string, []error) {
+	policy, preserved := config.DanglingWarn, config.DefaultPreserveProviders
+	if pc := origin(protocol, name); pc != nil {
+		preserved = pc.PreservedProviders()
+		if pc.DanglingReferences != "" {
+			policy = pc.DanglingReferences
+		}
+	}
+	exists := func(ref string, has func(string) bool) bool {
+		return ref == "" || config.IsPreserved(ref, preserved) || has(ref)
+	}
+
+	var errs []error
+	serviceMissing := !exists(service, hasService)
+	if serviceMissing {
+		errs = append(errs, fmt.Errorf("%s router %q: service %q not found", protocol, name, service))
+	}
+	kept := middlewares
+	var missing []string
+	if len(middlewares) > 0 {
+		kept = make([]string, 0, len(middlewares))
+		for _, mw := range middlewares {
+			if exists(mw, hasMiddleware) {
+				kept = append(kept, mw)
+				continue
+			}
+			missing = append(missing, mw)
+			errs = append(errs, fmt.Errorf("%s router %q: middleware %q not found", protocol, name, mw))
+		}
+	}
+	if len(errs) == 0 {
+		return false, middlewares, nil
+	}
+
+	switch policy {
+	case config.DanglingDropRouter:
+		errs = append(errs, fmt.Errorf("%s router %q: dropped", protocol, name))
+		return true, nil, errs
+	case config.DanglingDropMiddleware:
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s router %q: removed middlewares %v", protocol, name, missing))
+		}
+		return false, kept, errs
+	default:
+		return false, middlewares, errs
+	}
+}
+
+func hasKey[T any](m map[string]*T) func(string) bool {
+	return func(name string) bool {
+		_, ok := m[name]
+		return ok
+	}
+}
@@ -0,0 +1,128 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func originWith(policy string) RouterOrigin {
+	pc := &config.ProviderConfig{DanglingReferences: policy}
+	return func(protocol, router string) *config.ProviderConfig {
+		if router == "no-origin" {
+			return nil
+		}
+		return pc
+	}
+}
+
+func TestCheckReferences_Warn(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"ok":        {Service: "api", Middlewares: []string{"auth"}},
+			"internal":  {Service: "api@internal"},
+			"no-mw":     {Service: "api", Middlewares: []string{"auth", "gone"}},
+			"no-origin": {Service: "missing"},
+		},
+		Services:    map[string]*dynamic.Service{"api": {}},
+		Middlewares: map[string]*dynamic.Middleware{"auth": {}},
+	}}
+	errs := CheckReferences(cfg, originWith(""))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if len(cfg.HTTP.Routers) != 4 || !reflect.DeepEqual(cfg.HTTP.Routers["no-mw"].Middlewares, []string{"auth", "gone"}) {
+		t.Errorf("warn policy changed the routers: %+v", cfg.HTTP.Routers)
+	}
+}
+
+func TestCheckReferences_DropRouter(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"ok":        {Service: "api"},
+				"no-svc":    {Service: "missing"},
+				"no-origin": {Service: "missing"},
+			},
+			Services: map[string]*dynamic.Service{"api": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  map[string]*dynamic.TCPRouter{"db": {Service: "db", Middlewares: []string{"allow"}}},
+			Services: map[string]*dynamic.TCPService{"db": {}},
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers: map[string]*dynamic.UDPRouter{"dns": {Service: "dns"}},
+		},
+	}
+	CheckReferences(cfg, originWith(config.DanglingDropRouter))
+
+	if _, ok := cfg.HTTP.Routers["no-svc"]; ok {
+		t.Error("router with a missing service not dropped")
+	}
+	for _, name := range []string{"ok", "no-origin"} {
+		if _, ok := cfg.HTTP.Routers[name]; !ok {
+			t.Errorf("router %q dropped", name)
+		}
+	}
+	if len(cfg.TCP.Routers) != 0 || len(cfg.UDP.Routers) != 0 {
+		t.Errorf("tcp=%v udp=%v", cfg.TCP.Routers, cfg.UDP.Routers)
+	}
+}
+
+func TestCheckReferences_DropMiddleware(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"no-svc": {Service: "missing", Middlewares: []string{"auth"}},
+				"no-mw":  {Service: "api", Middlewares: []string{"auth", "gone"}},
+			},
+			Services:    map[string]*dynamic.Service{"api": {}},
+			Middlewares: map[string]*dynamic.Middleware{"auth": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  map[string]*dynamic.TCPRouter{"db": {Service: "db", Middlewares: []string{"allow"}}},
+			Services: map[string]*dynamic.TCPService{"db": {}},
+		},
+	}
+	CheckReferences(cfg, originWith(config.DanglingDropMiddleware))
+
+	if got := cfg.HTTP.Routers["no-mw"].Middlewares; !reflect.DeepEqual(got, []string{"auth"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+	if r := cfg.HTTP.Routers["no-svc"]; r == nil || !reflect.DeepEqual(r.Middlewares, []string{"auth"}) {
+		t.Errorf("router with a dangling service changed: %+v", r)
+	}
+	if got := cfg.TCP.Routers["db"].Middlewares; len(got) != 0 {
+		t.Errorf("tcp middlewares=%v", got)
+	}
+}
+
+func TestCheckReferences_Models(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Middlewares: map[string]*dynamic.Middleware{"auth": {}},
+		Models:      map[string]*dynamic.Model{"websecure": {Middlewares: []string{"auth", "gone", "noop@internal"}}},
+	}}
+	errs := CheckReferences(cfg, originWith(config.DanglingDropMiddleware))
+	if len(errs) != 1 {
+		t.Fatalf("expected the missing model middleware to be reported, got %v", errs)
+	}
+	if got := cfg.HTTP.Models["websecure"].Middlewares; len(got) != 3 {
+		t.Errorf("model changed: %v", got)
+	}
+}
+
+func TestCheckReferences_PreservedProviders(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{"r": {Service: "api@internal", Middlewares: []string{"auth@file"}}},
+	}}
+	pc := &config.ProviderConfig{PreserveProviders: []string{"file"}, DanglingReferences: config.DanglingDropMiddleware}
+	errs := CheckReferences(cfg, func(string, string) *config.ProviderConfig { return pc })
+	if len(errs) != 1 {
+		t.Fatalf("expected only the internal service to dangle, got %v", errs)
+	}
+	if got := cfg.HTTP.Routers["r"].Middlewares; !reflect.DeepEqual(got, []string{"auth@file"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package overrides
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+// depWalker walks the references held by a single resource.
+type depWalker func(fn refFunc)
+
+func depWalkers[T any](m map[string]*T, walk func(*T, refFunc)) map[string]depWalker {
+	out := make(map[string]depWalker, len(m))
+	for name, v := range m {
+		v := v
+		out[name] = func(fn refFunc) { walk(v, fn) }
+	}
+	return out
+}
+
+// HTTPDependencies returns the keys of the services and middlewares that
+// routers reference, directly or through weighted, mirroring and failover
+// children, chain members and error pages. services and middlewares are the
+// upstream maps keyed by full name; references to preserved providers are
+// not followed. routerProviders maps router names already stripped of their
+// provider postfix, such as those recorded by NewScope, to that provider.
+func HTTPDependencies(routers map[string]*dynamic.Router, routerProviders map[string]string, services map[string]*dynamic.Service, middlewares map[string]*dynamic.Middleware, preserved []string) (map[string]bool, map[string]bool) {
+	return dependencies(
+		depWalkers(routers, walkHTTPRouterRefs),
+		routerProviders,
+		depWalkers(services, walkHTTPServiceRefs),
+		depWalkers(middlewares, walkHTTPMiddlewareRefs),
+		preserved,
+	)
+}
+
+// TCPDependencies returns the keys of the TCP services and middlewares that
+// routers reference, directly or through weighted children.
+func TCPDependencies(routers map[string]*dynamic.TCPRouter, routerProviders map[string]string, services map[string]*dynamic.TCPService, middlewares map[string]*dynamic.TCPMiddleware, preserved []string) (map[string]bool, map[string]bool) {
+	return dependencies(
+		depWalkers(routers, walkTCPRouterRefs),
+		routerProviders,
+		depWalkers(services, walkTCPServiceRefs),
+		depWalkers(middlewares, func(*dynamic.TCPMiddleware, refFunc) {}),
+		preserved,
+	)
+}
+
+// UDPDependencies returns the keys of the UDP services that routers
+// reference, directly or through weighted children.
+func UDPDependencies(routers map[string]*dynamic.UDPRouter, routerProviders map[string]string, services map[string]*dynamic.UDPService, preserved []string) map[string]bool {
+	serviceKeys, _ := dependencies(
+		depWalkers(routers, func(r *dynamic.UDPRouter, fn refFunc) {
+			r.Service = walkRef(fn, refService, r.Service)
+		}),
+		routerProviders,
+		depWalkers(services, walkUDPServiceRefs),
+		nil,
+		preserved,
+	)
+	return serviceKeys
+}
+
+// dependencies computes the service and middleware keys reachable from roots.
+// A root without a provider postfix is looked up in rootProviders.
+func dependencies(roots map[string]depWalker, rootProviders map[string]string, services, middlewares map[string]depWalker, preserved []string) (map[string]bool, map[string]bool) {
+	serviceKeys, middlewareKeys := map[string]bool{}, map[string]bool{}
+	type item struct {
+		provider string
+		walk     depWalker
+	}
+	var queue []item
+	for _, name := range sortedKeys(roots) {
+		provider := providerOf(name)
+		if provider == "" {
+			provider = rootProviders[name]
+		}
+		queue = append(queue, item{provider, roots[name]})
+	}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		provider := it.provider
+		it.walk(func(kind refKind, ref string) string {
+			pool, seen := services, serviceKeys
+			switch kind {
+			case refService:
+			case refMiddleware:
+				pool, seen = middlewares, middlewareKeys
+			default:
+				return ref
+			}
+			if config.IsPreserved(ref, preserved) {
+				return ref
+			}
+			for _, key := range resolveRef(ref, provider, pool) {
+				if !seen[key] {
+					seen[key] = true
+					queue = append(queue, item{providerOf(key), pool[key]})
+				}
+			}
+			return ref
+		})
+	}
+	return serviceKeys, middlewareKeys
+}
+
+// resolveRef returns the keys of pool a reference made from provider points
+// at. A reference without a provider postfix resolves to the referrer's
+// provider first, then to every key with the same bare name.
+func resolveRef(ref, provider string, pool map[string]depWalker) []string {
+	if _, ok := pool[ref]; ok {
+		return []string{ref}
+	}
+	if strings.Contains(ref, "@") {
+		return nil
+	}
+	if provider != "" {
+		if _, ok := pool[ref+"@"+provider]; ok {
+			return []string{ref + "@" + provider}
+		}
+	}
+	var keys []string
+	for key := range pool {
+		if stripProvider(key) == ref {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// providerOf returns the provider postfix of name, or "" when it has none.
+func providerOf(name string) string {
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+)
+
+func TestHTTPDependencies(t *testing.T) {
+	routers := map[string]*dynamic.Router{
+		"api": {Service: "canary", Middlewares: []string{"secured", "auth@file"}},
+	}
+	services := map[string]*dynamic.Service{
+		"canary@docker":   {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api-v1"}, {Name: "api-v2@docker"}}}},
+		"api-v1@docker":   {Failover: &dynamic.Failover{Service: "primary", Fallback: "noop@internal"}},
+		"api-v2@docker":   {Mirroring: &dynamic.Mirroring{Service: "primary", Mirrors: []dynamic.MirrorService{{Name: "shadow"}}}},
+		"primary@docker":  {},
+		"shadow@docker":   {},
+		"error-page@file": {},
+		"unused@docker":   {},
+		"noop@internal":   {},
+	}
+	middlewares := map[string]*dynamic.Middleware{
+		"secured@docker": {Chain: &dynamic.Chain{Middlewares: []string{"errors"}}},
+		"errors@docker":  {Errors: &dynamic.ErrorPage{Service: "error-page@file"}},
+		"auth@file":      {},
+		"unused@docker":  {},
+	}
+
+	svc, mw := HTTPDependencies(routers, nil, services, middlewares, []string{"internal"})
+
+	wantSvc := map[string]bool{"canary@docker": true, "api-v1@docker": true, "api-v2@docker": true, "primary@docker": true, "shadow@docker": true, "error-page@file": true}
+	if !reflect.DeepEqual(svc, wantSvc) {
+		t.Errorf("services=%v want %v", svc, wantSvc)
+	}
+	wantMw := map[string]bool{"secured@docker": true, "errors@docker": true, "auth@file": true}
+	if !reflect.DeepEqual(mw, wantMw) {
+		t.Errorf("middlewares=%v want %v", mw, wantMw)
+	}
+}
+
+func TestHTTPDependencies_PrefersReferrerProvider(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"front@docker": {Failover: &dynamic.Failover{Service: "api"}},
+		"api@docker":   {},
+		"api@file":     {},
+	}
+	routers := map[string]*dynamic.Router{"front": {Service: "front@docker"}}
+
+	svc, _ := HTTPDependencies(routers, nil, services, nil, nil)
+	if !reflect.DeepEqual(svc, map[string]bool{"front@docker": true, "api@docker": true}) {
+		t.Errorf("services=%v", svc)
+	}
+
+	svc, _ = HTTPDependencies(map[string]*dynamic.Router{"r": {Service: "api"}}, nil, services, nil, nil)
+	if !reflect.DeepEqual(svc, map[string]bool{"api@docker": true, "api@file": true}) {
+		t.Errorf("bare reference from a stripped router: %v", svc)
+	}
+}
+
+func TestTCPAndUDPDependencies(t *testing.T) {
+	tcpSvc, tcpMw := TCPDependencies(
+		map[string]*dynamic.TCPRouter{"db": {Service: "db-wrr", Middlewares: []string{"allow"}}},
+		map[string]string{"db": "docker"},
+		map[string]*dynamic.TCPService{
+			"db-wrr@docker": {Weighted: &dynamic.TCPWeightedRoundRobin{Services: []dynamic.TCPWRRService{{Name: "db"}}}},
+			"db@docker":     {},
+			"other@docker":  {},
+		},
+		map[string]*dynamic.TCPMiddleware{"allow@docker": {}, "deny@docker": {}},
+		nil,
+	)
+	if !reflect.DeepEqual(tcpSvc, map[string]bool{"db-wrr@docker": true, "db@docker": true}) {
+		t.Errorf("tcp services=%v", tcpSvc)
+	}
+	if !reflect.DeepEqual(tcpMw, map[string]bool{"allow@docker": true}) {
+		t.Errorf("tcp middlewares=%v", tcpMw)
+	}
+
+	udpSvc := UDPDependencies(
+		map[string]*dynamic.UDPRouter{"dns": {Service: "dns-wrr"}},
+		nil,
+		map[string]*dynamic.UDPService{
+			"dns-wrr@docker": {Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns"}}}},
+			"dns@docker":     {},
+			"other@docker":   {},
+		},
+		nil,
+	)
+	if !reflect.DeepEqual(udpSvc, map[string]bool{"dns-wrr@docker": true, "dns@docker": true}) {
+		t.Errorf("udp services=%v", udpSvc)
+	}
+}
+
+func TestHTTPDependencies_RouterProvider(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"api@docker": {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "docker"}},
+		"api@file":   {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "file"}},
+	}
+	routers := map[string]*dynamic.Router{"api": {Service: "api"}}
+	for i := 0; i < 10; i++ {
+		svc, _ := HTTPDependencies(routers, map[string]string{"api": "docker"}, services, nil, nil)
+		if !reflect.DeepEqual(svc, map[string]bool{"api@docker": true}) {
+			t.Fatalf("services=%v", svc)
+		}
+	}
+}
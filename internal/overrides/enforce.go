@@ -0,0 +1,84 @@
+package overrides
+
+import (
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// EnforceMiddlewares places the enforced HTTP and TCP middlewares on the
+// routers of cfg. A nil enforce config leaves cfg unchanged.
+func EnforceMiddlewares(cfg *dynamic.Configuration, enforce *config.EnforceMiddlewaresConfig) {
+	if cfg == nil || enforce == nil {
+		return
+	}
+	if cfg.HTTP != nil {
+		EnforceHTTPMiddlewares(cfg.HTTP.Routers, enforce.HTTP)
+	}
+	if cfg.TCP != nil {
+		EnforceTCPMiddlewares(cfg.TCP.Routers, enforce.TCP)
+	}
+}
+
+// EnforceHTTPMiddlewares places each enforced middleware on every HTTP router
+// not matched by its exclusion matcher.
+func EnforceHTTPMiddlewares(routers map[string]*dynamic.Router, enforced []config.EnforceMiddleware) {
+	for _, e := range enforced {
+		excluded := map[string]*dynamic.Router{}
+		if e.Exclude != "" {
+			excluded = matchers.HTTPRouters(routers, &config.RoutersConfig{Matcher: e.Exclude, DiscoverPriority: true}, "")
+		}
+		for name, r := range routers {
+			if _, ok := excluded[name]; !ok {
+				r.Middlewares = placeMiddleware(r.Middlewares, e)
+			}
+		}
+	}
+}
+
+// EnforceTCPMiddlewares places each enforced middleware on every TCP router
+// not matched by its exclusion matcher.
+func EnforceTCPMiddlewares(routers map[string]*dynamic.TCPRouter, enforced []config.EnforceMiddleware) {
+	for _, e := range enforced {
+		excluded := map[string]*dynamic.TCPRouter{}
+		if e.Exclude != "" {
+			excluded = matchers.TCPRouters(routers, &config.RoutersConfig{Matcher: e.Exclude}, "")
+		}
+		for name, r := range routers {
+			if _, ok := excluded[name]; !ok {
+				r.Middlewares = placeMiddleware(r.Middlewares, e)
+			}
+		}
+	}
+}
+
+// placeMiddleware removes every occurrence of e.Name from current and inserts
+// it once at e.Position. Missing anchors and invalid positions place it last.
+func placeMiddleware(current []string, e config.EnforceMiddleware) []string {
+	out := make([]string, 0, len(current)+1)
+	for _, m := range current {
+		if m != e.Name {
+			out = append(out, m)
+		}
+	}
+	at := len(out)
+	kind, anchor, _ := config.ParsePosition(e.Position)
+	switch kind {
+	case config.PositionFirst:
+		at = 0
+	case config.PositionBefore, config.PositionAfter:
+		for i, m := range out {
+			if m == anchor {
+				at = i
+				if kind == config.PositionAfter {
+					at++
+				}
+				break
+			}
+		}
+	}
+	out = append(out, "")
+	copy(out[at+1:], out[at:])
+	out[at] = e.Name
+	return out
+}
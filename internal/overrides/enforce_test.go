@@ -0,0 +1,68 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestPlaceMiddleware(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  []string
+		position string
+		want     []string
+	}{
+		{name: "default last", current: []string{"a"}, want: []string{"a", "auth"}},
+		{name: "first", current: []string{"a", "b"}, position: "first", want: []string{"auth", "a", "b"}},
+		{name: "before", current: []string{"a", "b"}, position: "before:b", want: []string{"a", "auth", "b"}},
+		{name: "after", current: []string{"a", "b"}, position: "after:a", want: []string{"a", "auth", "b"}},
+		{name: "missing anchor", current: []string{"a"}, position: "before:x", want: []string{"a", "auth"}},
+		{name: "moves and dedupes", current: []string{"auth", "a", "auth"}, position: "last", want: []string{"a", "auth"}},
+		{name: "empty router", current: nil, position: "first", want: []string{"auth"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placeMiddleware(tt.current, config.EnforceMiddleware{Name: "auth", Position: tt.position})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforceMiddlewares(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{Routers: map[string]*dynamic.Router{
+			"api":    {Middlewares: []string{"ratelimit", "strip"}},
+			"health": {Middlewares: []string{"strip"}},
+		}},
+		TCP: &dynamic.TCPConfiguration{Routers: map[string]*dynamic.TCPRouter{
+			"db": {},
+		}},
+	}
+	enforce := &config.EnforceMiddlewaresConfig{
+		HTTP: []config.EnforceMiddleware{
+			{Name: "auth", Position: "first", Exclude: "Name(`health`)"},
+			{Name: "ratelimit", Position: "after:auth"},
+		},
+		TCP: []config.EnforceMiddleware{{Name: "allowlist"}},
+	}
+
+	EnforceMiddlewares(cfg, enforce)
+
+	if got := cfg.HTTP.Routers["api"].Middlewares; !reflect.DeepEqual(got, []string{"auth", "ratelimit", "strip"}) {
+		t.Errorf("api middlewares=%v", got)
+	}
+	if got := cfg.HTTP.Routers["health"].Middlewares; !reflect.DeepEqual(got, []string{"strip", "ratelimit"}) {
+		t.Errorf("health middlewares=%v", got)
+	}
+	if got := cfg.TCP.Routers["db"].Middlewares; !reflect.DeepEqual(got, []string{"allowlist"}) {
+		t.Errorf("db middlewares=%v", got)
+	}
+
+	EnforceMiddlewares(cfg, nil)
+	EnforceMiddlewares(&dynamic.Configuration{}, enforce)
+}
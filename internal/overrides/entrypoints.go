@@ -0,0 +1,64 @@
+package overrides
+
+import (
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+// MapHTTPEntrypoints renames the entrypoints of HTTP routers according to m.
+func MapHTTPEntrypoints(routers map[string]*dynamic.Router, m *config.EntrypointMapConfig) {
+	mapEntrypoints(routers, m, func(r *dynamic.Router) *[]string { return &r.EntryPoints })
+}
+
+// MapTCPEntrypoints renames the entrypoints of TCP routers according to m.
+func MapTCPEntrypoints(routers map[string]*dynamic.TCPRouter, m *config.EntrypointMapConfig) {
+	mapEntrypoints(routers, m, func(r *dynamic.TCPRouter) *[]string { return &r.EntryPoints })
+}
+
+// MapUDPEntrypoints renames the entrypoints of UDP routers according to m.
+func MapUDPEntrypoints(routers map[string]*dynamic.UDPRouter, m *config.EntrypointMapConfig) {
+	mapEntrypoints(routers, m, func(r *dynamic.UDPRouter) *[]string { return &r.EntryPoints })
+}
+
+// mapEntrypoints rewrites the entrypoints returned by eps for every router.
+// Names without a mapping are handled according to m.Unmapped, duplicates
+// produced by the mapping are removed, and routers left without entrypoints
+// get m.Defaults.
+func mapEntrypoints[R any](routers map[string]*R, m *config.EntrypointMapConfig, eps func(r *R) *[]string) {
+	if m == nil {
+		return
+	}
+	for name, r := range routers {
+		current := eps(r)
+		mapped := make([]string, 0, len(*current))
+		seen := make(map[string]bool, len(*current))
+		drop := false
+		for _, ep := range *current {
+			target, ok := m.Mappings[ep]
+			if !ok {
+				switch m.Unmapped {
+				case config.UnmappedDrop:
+					continue
+				case config.UnmappedDropRouter:
+					drop = true
+				}
+				target = ep
+			}
+			if !seen[target] {
+				seen[target] = true
+				mapped = append(mapped, target)
+			}
+		}
+		if drop {
+			delete(routers, name)
+			continue
+		}
+		if len(mapped) == 0 {
+			mapped = append(mapped, m.Defaults...)
+		}
+		if len(mapped) == 0 {
+			mapped = nil
+		}
+		*current = mapped
+	}
+}
@@ -0,0 +1,70 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestMapHTTPEntrypoints(t *testing.T) {
+	m := &config.EntrypointMapConfig{
+		Mappings: map[string]string{"http": "web", "https": "websecure", "http-alt": "web"},
+		Defaults: []string{"web"},
+	}
+	cases := []struct {
+		name     string
+		unmapped string
+		in       []string
+		want     []string
+		dropped  bool
+	}{
+		{name: "mapped", in: []string{"http", "https"}, want: []string{"web", "websecure"}},
+		{name: "dedupes", in: []string{"http", "http-alt"}, want: []string{"web"}},
+		{name: "keep unmapped", in: []string{"https", "metrics"}, want: []string{"websecure", "metrics"}},
+		{name: "drop unmapped", unmapped: config.UnmappedDrop, in: []string{"metrics", "https"}, want: []string{"websecure"}},
+		{name: "drop leaves defaults", unmapped: config.UnmappedDrop, in: []string{"metrics"}, want: []string{"web"}},
+		{name: "drop router", unmapped: config.UnmappedDropRouter, in: []string{"https", "metrics"}, dropped: true},
+		{name: "defaults", in: nil, want: []string{"web"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *m
+			cfg.Unmapped = tt.unmapped
+			routers := map[string]*dynamic.Router{"r": {EntryPoints: tt.in}}
+			MapHTTPEntrypoints(routers, &cfg)
+			r, ok := routers["r"]
+			if ok == tt.dropped {
+				t.Fatalf("router present=%v, want dropped=%v", ok, tt.dropped)
+			}
+			if ok && !reflect.DeepEqual(r.EntryPoints, tt.want) {
+				t.Errorf("entrypoints=%v want %v", r.EntryPoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapTCPAndUDPEntrypoints(t *testing.T) {
+	m := &config.EntrypointMapConfig{Mappings: map[string]string{"internal-grpc": "grpc", "dns": "udp-dns"}}
+
+	tcp := map[string]*dynamic.TCPRouter{"db": {EntryPoints: []string{"internal-grpc"}}, "none": {}}
+	MapTCPEntrypoints(tcp, m)
+	if !reflect.DeepEqual(tcp["db"].EntryPoints, []string{"grpc"}) {
+		t.Errorf("tcp entrypoints=%v", tcp["db"].EntryPoints)
+	}
+	if tcp["none"].EntryPoints != nil {
+		t.Errorf("expected no entrypoints without defaults, got %v", tcp["none"].EntryPoints)
+	}
+
+	udp := map[string]*dynamic.UDPRouter{"dns": {EntryPoints: []string{"dns"}}}
+	MapUDPEntrypoints(udp, m)
+	if !reflect.DeepEqual(udp["dns"].EntryPoints, []string{"udp-dns"}) {
+		t.Errorf("udp entrypoints=%v", udp["dns"].EntryPoints)
+	}
+
+	MapUDPEntrypoints(udp, nil)
+	if !reflect.DeepEqual(udp["dns"].EntryPoints, []string{"udp-dns"}) {
+		t.Errorf("nil map changed entrypoints: %v", udp["dns"].EntryPoints)
+	}
+}
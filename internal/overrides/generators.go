@@ -0,0 +1,204 @@
+package overrides
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// renderFunc renders a compiled template tree for one source resource.
+type renderFunc func(data TemplateData) (interface{}, error)
+
+// Generate renders each generator once per upstream resource it selects and
+// adds the decoded results to target. sources maps a generator source to the
+// raw upstream resources of that kind, and defaultSource is used for
+// generators without one. Generated names without a provider suffix inherit
+// the suffix of their source so they are stripped like discovered resources.
+// Resources that fail to render or decode, or whose name is already taken, are
+// skipped and returned as errors.
+func Generate[T any](target map[string]*T, gens []config.Generator, sources map[string]interface{}, defaultSource, providerMatcher, providerConfigName string) []error {
+	var errs []error
+	providerProg, err := rules.Compile(providerMatcher)
+	if err != nil {
+		return []error{fmt.Errorf("provider matcher %q: %w", providerMatcher, err)}
+	}
+	for i, g := range gens {
+		source := g.Source
+		if source == "" {
+			source = defaultSource
+		}
+		prog, name, render, err := compileGenerator(g)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("generator %d: %w", i, err))
+			continue
+		}
+		items, _ := sources[source].(map[string]interface{})
+		for _, srcName := range sortedKeys(items) {
+			data := sourceData(srcName, items[srcName], providerConfigName)
+			ctx := rules.Context{Name: srcName, Provider: data.Provider, Entrypoints: data.Entrypoints, Service: rawString(items[srcName], "service")}
+			if !providerProg.Match(ctx) || !prog.Match(ctx) {
+				continue
+			}
+			data.Captures = prog.Captures(ctx)
+			key, resource, err := generate[T](name, render, data)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("generator %d: source %q: %w", i, srcName, err))
+				continue
+			}
+			if _, exists := target[key]; exists {
+				errs = append(errs, fmt.Errorf("generator %d: source %q: %q already exists", i, srcName, key))
+				continue
+			}
+			target[key] = resource
+		}
+	}
+	return errs
+}
+
+func compileGenerator(g config.Generator) (*rules.Program, renderFunc, renderFunc, error) {
+	prog, err := rules.Compile(g.Matcher)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("matcher %q: %w", g.Matcher, err)
+	}
+	name, err := compileTree(g.Name)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("name: %w", err)
+	}
+	tree, err := toJSONValue(g.Template)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("template: %w", err)
+	}
+	render, err := compileTree(tree)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("template: %w", err)
+	}
+	return prog, name, render, nil
+}
+
+// generate renders the name and resource of one generated object.
+func generate[T any](name, render renderFunc, data TemplateData) (string, *T, error) {
+	renderedName, err := name(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("name: %w", err)
+	}
+	key := renderedName.(string)
+	if key == "" {
+		return "", nil, fmt.Errorf("name rendered empty")
+	}
+	if !strings.Contains(key, "@") && data.Provider != "" {
+		key += "@" + data.Provider
+	}
+	doc, err := render(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("template: %w", err)
+	}
+	resource, err := decodeResource[T](doc)
+	if err != nil {
+		return "", nil, fmt.Errorf("template: %w", err)
+	}
+	return key, resource, nil
+}
+
+// compileTree compiles every string in a JSON value tree as a template.
+func compileTree(v interface{}) (renderFunc, error) {
+	switch t := v.(type) {
+	case string:
+		tmpl, err := parseTemplate(t)
+		if err != nil {
+			return nil, err
+		}
+		return func(data TemplateData) (interface{}, error) {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return nil, err
+			}
+			return buf.String(), nil
+		}, nil
+	case map[string]interface{}:
+		fields := make(map[string]renderFunc, len(t))
+		for k, child := range t {
+			f, err := compileTree(child)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			fields[k] = f
+		}
+		return func(data TemplateData) (interface{}, error) {
+			out := make(map[string]interface{}, len(fields))
+			for k, f := range fields {
+				r, err := f(data)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", k, err)
+				}
+				out[k] = r
+			}
+			return out, nil
+		}, nil
+	case []interface{}:
+		items := make([]renderFunc, 0, len(t))
+		for i, child := range t {
+			f, err := compileTree(child)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			items = append(items, f)
+		}
+		return func(data TemplateData) (interface{}, error) {
+			out := make([]interface{}, 0, len(items))
+			for i, f := range items {
+				r, err := f(data)
+				if err != nil {
+					return nil, fmt.Errorf("%d: %w", i, err)
+				}
+				out = append(out, r)
+			}
+			return out, nil
+		}, nil
+	default:
+		return func(TemplateData) (interface{}, error) { return t, nil }, nil
+	}
+}
+
+// sourceData builds the template variables for a raw upstream resource.
+func sourceData(name string, item interface{}, providerConfigName string) TemplateData {
+	data := TemplateData{
+		Name:               stripProvider(name),
+		ProviderConfigName: providerConfigName,
+		Rule:               rawString(item, "rule"),
+		Service:            stripProvider(rawString(item, "service")),
+	}
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		data.Provider = name[i+1:]
+	}
+	if m, ok := item.(map[string]interface{}); ok {
+		if eps, ok := m["entryPoints"].([]interface{}); ok {
+			for _, ep := range eps {
+				if s, ok := ep.(string); ok {
+					data.Entrypoints = append(data.Entrypoints, s)
+				}
+			}
+		}
+	}
+	return data
+}
+
+func rawString(item interface{}, key string) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		s, _ := m[key].(string)
+		return s
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
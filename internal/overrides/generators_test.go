@@ -0,0 +1,96 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestGenerate_RoutersFromServices(t *testing.T) {
+	sources := map[string]interface{}{
+		config.SourceServices: map[string]interface{}{
+			"api@docker":    map[string]interface{}{"loadBalancer": map[string]interface{}{}},
+			"web@docker":    map[string]interface{}{"loadBalancer": map[string]interface{}{}},
+			"noop@internal": map[string]interface{}{},
+		},
+	}
+	gens := []config.Generator{{
+		Source:  config.SourceServices,
+		Matcher: "NameRegexp(`^(a.*)@`) || Name(`web@docker`)",
+		Name:    "{{.Name}}-internal",
+		Template: map[string]interface{}{
+			"rule":        "Host(`{{.Name}}.svc.local`)",
+			"service":     "{{.Name}}",
+			"entryPoints": []interface{}{"internal"},
+		},
+	}}
+	routers := map[string]*dynamic.Router{}
+
+	if errs := Generate(routers, gens, sources, config.SourceRouters, "Provider(`docker`)", "upstream"); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(routers) != 2 {
+		t.Fatalf("expected 2 generated routers, got %v", routers)
+	}
+	api := routers["api-internal@docker"]
+	if api == nil || api.Rule != "Host(`api.svc.local`)" || api.Service != "api" || !reflect.DeepEqual(api.EntryPoints, []string{"internal"}) {
+		t.Errorf("unexpected api router: %+v", api)
+	}
+	if routers["web-internal@docker"] == nil {
+		t.Error("expected web-internal router")
+	}
+}
+
+func TestGenerate_RouterVariablesAndCaptures(t *testing.T) {
+	sources := map[string]interface{}{
+		config.SourceRouters: map[string]interface{}{
+			"shop@file": map[string]interface{}{"rule": "Host(`shop`)", "service": "shop@file", "entryPoints": []interface{}{"websecure"}},
+			"api@file":  map[string]interface{}{"rule": "Host(`api`)", "service": "api@file", "entryPoints": []interface{}{"web"}},
+		},
+	}
+	gens := []config.Generator{{
+		Matcher: "Entrypoint(`websecure`) && NameRegexp(`^(\\w+)@`)",
+		Name:    "{{index .Captures 1}}-redirect@gen",
+		Template: map[string]interface{}{
+			"rule":        "{{.Rule}}",
+			"service":     "{{.Service}}",
+			"entryPoints": []interface{}{"web"},
+			"middlewares": []interface{}{"{{.ProviderConfigName | lower}}-redirect"},
+		},
+	}}
+	routers := map[string]*dynamic.Router{}
+
+	if errs := Generate(routers, gens, sources, config.SourceRouters, "", "Edge"); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := &dynamic.Router{Rule: "Host(`shop`)", Service: "shop", EntryPoints: []string{"web"}, Middlewares: []string{"edge-redirect"}}
+	if got := routers["shop-redirect@gen"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+	if len(routers) != 1 {
+		t.Errorf("expected only the websecure router to generate, got %v", routers)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	sources := map[string]interface{}{
+		config.SourceMiddlewares: map[string]interface{}{"auth@file": map[string]interface{}{}},
+	}
+	middlewares := map[string]*dynamic.Middleware{"auth-copy@file": {}}
+	gens := []config.Generator{
+		{Name: "{{.Name}}-copy", Template: map[string]interface{}{}},
+		{Name: "{{.Missing}}", Template: map[string]interface{}{}},
+		{Name: "{{.Name}}-bad", Template: map[string]interface{}{"notAMiddleware": "x"}},
+		{Name: "{{", Template: map[string]interface{}{}},
+	}
+
+	errs := Generate(middlewares, gens, sources, config.SourceMiddlewares, "", "")
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	if len(middlewares) != 1 {
+		t.Errorf("expected no generated middlewares, got %v", middlewares)
+	}
+}
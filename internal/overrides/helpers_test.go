@@ -7,7 +7,7 @@ import (
 	"github.com/traefik/genconf/dynamic"
 )
 
-func TestApplyRouterOverride_UpdatesRule(t *testing.T) {
+func TestTemplateOverride_UpdatesRule(t *testing.T) {
 	matched := map[string]*dynamic.Router{
 		"test-router": {
 			Rule:    "Host(`example.com`)",
@@ -19,9 +19,12 @@ func TestApplyRouterOverride_UpdatesRule(t *testing.T) {
 
 	value := "new-rule"
 
-	applyRouterOverride(matched, match, value, func(r *dynamic.Router, v string) {
+	errs := templateOverride(matched, match, value, routerTemplateData, nil, func(r *dynamic.Router, v string) {
 		r.Rule = v
 	})
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
 
 	// Assert that rule was updated
 	if got := matched["test-router"].Rule; got != "new-rule" {
@@ -29,12 +32,12 @@ func TestApplyRouterOverride_UpdatesRule(t *testing.T) {
 	}
 }
 
-func TestHandleRouterOverride_SetsEntryPoints(t *testing.T) {
+func TestTemplateOverride_SetsEntryPoints(t *testing.T) {
 	// string value -> single entrypoint slice
 	matched := map[string]*dynamic.Router{
 		"r1": {EntryPoints: []string{}},
 	}
-	handleRouterOverride(matched, "", "web",
+	templateOverride(matched, "", "web", routerTemplateData,
 		func(r *dynamic.Router, arr []string) { r.EntryPoints = arr },
 		func(r *dynamic.Router, s string) { r.EntryPoints = []string{s} },
 	)
@@ -46,7 +49,7 @@ func TestHandleRouterOverride_SetsEntryPoints(t *testing.T) {
 	matched = map[string]*dynamic.Router{
 		"r2": {EntryPoints: []string{}},
 	}
-	handleRouterOverride(matched, "", []string{"web", "websecure"},
+	templateOverride(matched, "", []string{"web", "websecure"}, routerTemplateData,
 		func(r *dynamic.Router, arr []string) { r.EntryPoints = arr },
 		func(r *dynamic.Router, s string) { r.EntryPoints = []string{s} },
 	)
@@ -55,6 +58,10 @@ func TestHandleRouterOverride_SetsEntryPoints(t *testing.T) {
 	}
 }
 
+func routerTemplateData(name string, r *dynamic.Router) TemplateData {
+	return routerData(Scope{}, name, r.Rule, r.Service, r.EntryPoints)
+}
+
 func TestApplyServiceOverride(t *testing.T) {
 	matched := map[string]*dynamic.Service{
 		"test-service": {
@@ -159,26 +166,6 @@ func TestStripProviderFromKeys(t *testing.T) {
 	}
 }
 
-func TestStripProviderRefsRouter_HTTP(t *testing.T) {
-	service := "svc@file"
-	mids := []string{"m1@file", "m2"}
-	StripProviderRefsRouter(&service, &mids)
-	if service != "svc" {
-		t.Fatalf("service=%q", service)
-	}
-	if !reflect.DeepEqual(mids, []string{"m1", "m2"}) {
-		t.Fatalf("middlewares=%v", mids)
-	}
-}
-
-func TestStripProviderRefsRouter_UDP(t *testing.T) {
-	service := "svc@f"
-	StripProviderRefsRouter(&service, nil)
-	if service != "svc" {
-		t.Fatalf("service=%q", service)
-	}
-}
-
 func TestStripProvidersHTTP(t *testing.T) {
 	httpCfg := &dynamic.HTTPConfiguration{
 		Routers: map[string]*dynamic.Router{
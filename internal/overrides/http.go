@@ -2,68 +2,79 @@
 package overrides
 
 import (
-	"strings"
-
 	"github.com/traefik/genconf/dynamic"
 	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
 )
 
 // OverrideHTTPRouters applies override rules to the given HTTP routers map.
-func OverrideHTTPRouters(matched map[string]*dynamic.Router, overrides config.RouterOverrides) {
+// Override values are rendered as templates for each matched router; "$1" in
+// rule and service values is replaced with the current value.
+func OverrideHTTPRouters(matched map[string]*dynamic.Router, overrides config.RouterOverrides, scope Scope) []error {
+	var errs []error
+	sel := func(matcher string) map[string]*dynamic.Router {
+		return matchers.HTTPRouters(matched, &config.RoutersConfig{Matcher: matcher, DiscoverPriority: true}, "")
+	}
+	data := func(name string, r *dynamic.Router) TemplateData {
+		return routerData(scope, name, r.Rule, r.Service, r.EntryPoints)
+	}
+
 	// Rule overrides
 	for _, orule := range overrides.Rules {
-		applyRouterOverride(matched, orule.Matcher, orule.Value, func(r *dynamic.Router, v string) {
-			if strings.Contains(v, "$1") {
-				r.Rule = strings.ReplaceAll(v, "$1", r.Rule)
-			} else {
-				r.Rule = v
-			}
-		})
+		errs = append(errs, prefixErrors("router rule override", templateOverride(sel(orule.Matcher), orule.Matcher, orule.Value, data,
+			nil,
+			func(r *dynamic.Router, v string) { r.Rule = replaceOld(v, r.Rule) },
+		))...)
 	}
 
 	// Entrypoint overrides
 	for _, oep := range overrides.Entrypoints {
-		handleRouterOverride(matched, oep.Matcher, oep.Value,
+		errs = append(errs, prefixErrors("router entrypoint override", templateOverride(sel(oep.Matcher), oep.Matcher, oep.Value, data,
 			func(r *dynamic.Router, arr []string) { r.EntryPoints = arr },
 			func(r *dynamic.Router, s string) { r.EntryPoints = append(r.EntryPoints, s) },
-		)
+		))...)
 	}
 
 	// Service overrides
 	for _, osvc := range overrides.Services {
-		applyRouterOverride(matched, osvc.Matcher, osvc.Value, func(r *dynamic.Router, v string) {
-			if strings.Contains(v, "$1") {
-				r.Service = strings.ReplaceAll(v, "$1", r.Service)
-			} else {
-				r.Service = v
-			}
-		})
+		errs = append(errs, prefixErrors("router service override", templateOverride(sel(osvc.Matcher), osvc.Matcher, osvc.Value, data,
+			nil,
+			func(r *dynamic.Router, v string) { r.Service = replaceOld(v, r.Service) },
+		))...)
 	}
 
 	// Middlewares overrides
 	for _, omw := range overrides.Middlewares {
-		handleRouterOverride(matched, omw.Matcher, omw.Value,
+		errs = append(errs, prefixErrors("router middleware override", templateOverride(sel(omw.Matcher), omw.Matcher, omw.Value, data,
 			func(r *dynamic.Router, arr []string) { r.Middlewares = arr },
 			func(r *dynamic.Router, s string) { r.Middlewares = append(r.Middlewares, s) },
-		)
+		))...)
 	}
+	return errs
 }
 
 // OverrideHTTPServices applies overrides to matched HTTP services.
-// If a server override specifies a Tunnel, the matched services' servers are
-// replaced with the tunnel addresses.
-func OverrideHTTPServices(matched map[string]*dynamic.Service, overrides config.ServiceOverrides, tunnels []config.TunnelConfig) {
+// Server overrides only affect services with a load balancer. Invalid server
+// overrides are skipped and returned as errors.
+func OverrideHTTPServices(matched map[string]*dynamic.Service, overrides config.ServiceOverrides, tunnels []config.TunnelConfig) []error {
+	var errs []error
 	// Server overrides
 	for _, orule := range overrides.Servers {
-		// Otherwise apply value-based overrides
-		handleServiceOverride(matched, orule.Matcher, orule.Value,
-			func(s *dynamic.Service, v []string) {
-				s.LoadBalancer.Servers = buildServers(v)
-			},
-			func(s *dynamic.Service, v string) {
-				s.LoadBalancer.Servers = append(s.LoadBalancer.Servers, dynamic.Server{URL: v})
-			},
-		)
+		edit, err := newServerEdit(orule)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		applyServiceOverride(matched, orule.Matcher, edit, func(s *dynamic.Service, e *serverEdit) {
+			if s.LoadBalancer == nil {
+				return
+			}
+			urls := make([]string, 0, len(s.LoadBalancer.Servers))
+			for _, srv := range s.LoadBalancer.Servers {
+				urls = append(urls, srv.URL)
+			}
+			s.LoadBalancer.Servers = buildServers(e.apply(urls))
+		})
 	}
 	// Healthcheck overrides
 	for _, ohc := range overrides.Healthchecks {
@@ -71,6 +82,11 @@ func OverrideHTTPServices(matched map[string]*dynamic.Service, overrides config.
 			applyHealthcheck(s, hc)
 		})
 	}
+	// Load balancer overrides
+	for _, olb := range overrides.LoadBalancers {
+		applyServiceOverride(matched, olb.Matcher, olb, applyLoadBalancer)
+	}
+	return errs
 }
 
 // buildServers converts a list of URLs to dynamic.Server slice.
@@ -85,18 +101,105 @@ func buildServers(urls []string) []dynamic.Server {
 	return servers
 }
 
-// applyHealthcheck applies non-empty healthcheck fields.
+// applyHealthcheck removes the healthcheck or applies non-empty healthcheck
+// fields, creating the healthcheck when missing.
 func applyHealthcheck(s *dynamic.Service, hc config.OverrideHealthcheck) {
-	if s.LoadBalancer == nil || s.LoadBalancer.HealthCheck == nil {
+	if s.LoadBalancer == nil {
+		return
+	}
+	if hc.Remove {
+		s.LoadBalancer.HealthCheck = nil
 		return
 	}
+	if s.LoadBalancer.HealthCheck == nil {
+		s.LoadBalancer.HealthCheck = &dynamic.ServerHealthCheck{}
+	}
+	target := s.LoadBalancer.HealthCheck
+	if hc.Scheme != "" {
+		target.Scheme = hc.Scheme
+	}
 	if hc.Path != "" {
-		s.LoadBalancer.HealthCheck.Path = hc.Path
+		target.Path = hc.Path
+	}
+	if hc.Method != "" {
+		target.Method = hc.Method
+	}
+	if hc.Port != 0 {
+		target.Port = hc.Port
 	}
 	if hc.Interval != "" {
-		s.LoadBalancer.HealthCheck.Interval = hc.Interval
+		target.Interval = hc.Interval
 	}
 	if hc.Timeout != "" {
-		s.LoadBalancer.HealthCheck.Timeout = hc.Timeout
+		target.Timeout = hc.Timeout
+	}
+	if hc.Hostname != "" {
+		target.Hostname = hc.Hostname
+	}
+	if hc.FollowRedirects != nil {
+		followRedirects := *hc.FollowRedirects
+		target.FollowRedirects = &followRedirects
+	}
+	if len(hc.Headers) > 0 {
+		if target.Headers == nil {
+			target.Headers = make(map[string]string, len(hc.Headers))
+		}
+		for k, v := range hc.Headers {
+			target.Headers[k] = v
+		}
+	}
+}
+
+// applyLoadBalancer applies the HTTP load balancer settings that are set in lb.
+func applyLoadBalancer(s *dynamic.Service, lb config.OverrideLoadBalancer) {
+	if s.LoadBalancer == nil {
+		return
+	}
+	target := s.LoadBalancer
+	if lb.Sticky != nil {
+		applySticky(target, lb.Sticky)
+	}
+	if lb.PassHostHeader != nil {
+		passHostHeader := *lb.PassHostHeader
+		target.PassHostHeader = &passHostHeader
+	}
+	if lb.ResponseForwarding != nil && lb.ResponseForwarding.FlushInterval != "" {
+		if target.ResponseForwarding == nil {
+			target.ResponseForwarding = &dynamic.ResponseForwarding{}
+		}
+		target.ResponseForwarding.FlushInterval = lb.ResponseForwarding.FlushInterval
+	}
+	if lb.ServersTransport != "" {
+		target.ServersTransport = lb.ServersTransport
+	}
+}
+
+// applySticky enables, updates or removes sticky sessions on a load balancer.
+func applySticky(target *dynamic.ServersLoadBalancer, sticky *config.OverrideSticky) {
+	if sticky.Remove {
+		target.Sticky = nil
+		return
+	}
+	if target.Sticky == nil {
+		target.Sticky = &dynamic.Sticky{}
+	}
+	if target.Sticky.Cookie == nil {
+		target.Sticky.Cookie = &dynamic.Cookie{}
+	}
+	if sticky.Cookie == nil {
+		return
+	}
+	cookie := target.Sticky.Cookie
+	if sticky.Cookie.Name != "" {
+		cookie.Name = sticky.Cookie.Name
+	}
+	if sticky.Cookie.Secure != nil {
+		cookie.Secure = *sticky.Cookie.Secure
+	}
+	if sticky.Cookie.HTTPOnly != nil {
+		cookie.HTTPOnly = *sticky.Cookie.HTTPOnly
+	}
+	if sticky.Cookie.SameSite != "" {
+		cookie.SameSite = sticky.Cookie.SameSite
 	}
 }
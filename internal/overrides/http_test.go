@@ -17,7 +17,7 @@ func TestOverrideHTTPRouters_RuleOverride(t *testing.T) {
 			Value:   "Host(`new.example.com`)",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	if routers["test-router"].Rule != "Host(`new.example.com`)" {
 		t.Errorf("Expected rule 'Host(`new.example.com`)', got %s", routers["test-router"].Rule)
 	}
@@ -33,7 +33,7 @@ func TestOverrideHTTPRouters_RuleOverrideWithDollar(t *testing.T) {
 			Value:   "$1 && PathPrefix(`/v1`)",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	expected := "Host(`api.example.com`) && PathPrefix(`/v1`)"
 	if routers["api-router"].Rule != expected {
 		t.Errorf("Expected rule '%s', got %s", expected, routers["api-router"].Rule)
@@ -50,7 +50,7 @@ func TestOverrideHTTPRouters_ServiceOverride(t *testing.T) {
 			Value:   "new-service",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	if routers["service-router"].Service != "new-service" {
 		t.Errorf("Expected service 'new-service', got %s", routers["service-router"].Service)
 	}
@@ -66,7 +66,7 @@ func TestOverrideHTTPRouters_ServiceOverrideWithDollar(t *testing.T) {
 			Value:   "$1-v2",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	if routers["service-router"].Service != "old-service-v2" {
 		t.Errorf("Expected service 'old-service-v2', got %s", routers["service-router"].Service)
 	}
@@ -82,7 +82,7 @@ func TestOverrideHTTPRouters_EntrypointsString(t *testing.T) {
 			Value:   "websecure",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	expected := []string{"web", "websecure"}
 	if len(routers["ep-router"].EntryPoints) != 2 || routers["ep-router"].EntryPoints[1] != "websecure" {
 		t.Errorf("Expected entrypoints %v, got %v", expected, routers["ep-router"].EntryPoints)
@@ -99,7 +99,7 @@ func TestOverrideHTTPRouters_EntrypointsArray(t *testing.T) {
 			Value:   []string{"web", "websecure"},
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	expected := []string{"web", "websecure"}
 	if len(routers["ep-router"].EntryPoints) != 2 {
 		t.Errorf("Expected entrypoints %v, got %v", expected, routers["ep-router"].EntryPoints)
@@ -116,7 +116,7 @@ func TestOverrideHTTPRouters_MiddlewaresString(t *testing.T) {
 			Value:   "cors",
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	expected := []string{"auth", "cors"}
 	if len(routers["mw-router"].Middlewares) != 2 || routers["mw-router"].Middlewares[1] != "cors" {
 		t.Errorf("Expected middlewares %v, got %v", expected, routers["mw-router"].Middlewares)
@@ -133,7 +133,7 @@ func TestOverrideHTTPRouters_MiddlewaresArray(t *testing.T) {
 			Value:   []string{"cors", "ratelimit"},
 		}},
 	}
-	OverrideHTTPRouters(routers, overrides)
+	OverrideHTTPRouters(routers, overrides, Scope{})
 	expected := []string{"cors", "ratelimit"}
 	if len(routers["mw-router"].Middlewares) != 2 || routers["mw-router"].Middlewares[0] != "cors" || routers["mw-router"].Middlewares[1] != "ratelimit" {
 		t.Errorf("Expected middlewares %v, got %v", expected, routers["mw-router"].Middlewares)
@@ -249,12 +249,75 @@ func TestOverrideHTTPServices_NoHealthcheck(t *testing.T) {
 		}},
 	}
 
-	// Should not panic when health check is nil
+	// A missing health check is created from the override
 	OverrideHTTPServices(services, overrides, nil)
 
-	// Service should remain unchanged
-	if services["no-hc-service"].LoadBalancer.HealthCheck != nil {
-		t.Error("Expected health check to remain nil")
+	hc := services["no-hc-service"].LoadBalancer.HealthCheck
+	if hc == nil {
+		t.Fatal("Expected health check to be created")
+	}
+	if hc.Path != "/health" {
+		t.Errorf("Expected health check path '/health', got %s", hc.Path)
+	}
+}
+
+func TestOverrideHTTPServices_HealthcheckAllFields(t *testing.T) {
+	follow := false
+	services := map[string]*dynamic.Service{
+		"svc": {
+			LoadBalancer: &dynamic.ServersLoadBalancer{
+				HealthCheck: &dynamic.ServerHealthCheck{Path: "/old", Headers: map[string]string{"X-Old": "1"}},
+			},
+		},
+	}
+
+	overrides := config.ServiceOverrides{
+		Healthchecks: []config.OverrideHealthcheck{{
+			Matcher:         "Name(`svc`)",
+			Scheme:          "https",
+			Method:          "HEAD",
+			Port:            8443,
+			Hostname:        "health.internal",
+			Headers:         map[string]string{"X-Check": "aggregator"},
+			FollowRedirects: &follow,
+		}},
+	}
+
+	OverrideHTTPServices(services, overrides, nil)
+
+	hc := services["svc"].LoadBalancer.HealthCheck
+	if hc.Scheme != "https" || hc.Method != "HEAD" || hc.Port != 8443 || hc.Hostname != "health.internal" || hc.Path != "/old" {
+		t.Errorf("unexpected health check: %+v", hc)
+	}
+	if hc.FollowRedirects == nil || *hc.FollowRedirects {
+		t.Errorf("expected followRedirects=false, got %v", hc.FollowRedirects)
+	}
+	if hc.Headers["X-Old"] != "1" || hc.Headers["X-Check"] != "aggregator" {
+		t.Errorf("expected merged headers, got %v", hc.Headers)
+	}
+}
+
+func TestOverrideHTTPServices_HealthcheckRemove(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"svc": {
+			LoadBalancer: &dynamic.ServersLoadBalancer{
+				HealthCheck: &dynamic.ServerHealthCheck{Path: "/health"},
+			},
+		},
+		"weighted": {Weighted: &dynamic.WeightedRoundRobin{}},
+	}
+
+	overrides := config.ServiceOverrides{
+		Healthchecks: []config.OverrideHealthcheck{{Matcher: "NameRegexp(`.*`)", Remove: true, Path: "/ignored"}},
+	}
+
+	OverrideHTTPServices(services, overrides, nil)
+
+	if services["svc"].LoadBalancer.HealthCheck != nil {
+		t.Errorf("expected health check to be removed, got %+v", services["svc"].LoadBalancer.HealthCheck)
+	}
+	if services["weighted"].LoadBalancer != nil {
+		t.Error("expected weighted service to be left alone")
 	}
 }
 
@@ -290,3 +353,69 @@ func TestOverrideHTTPServices_ServerOverrideArrayReplaces(t *testing.T) {
 		t.Fatalf("expected replace with two servers, got: %+v", got)
 	}
 }
+
+func TestOverrideHTTPServices_LoadBalancer(t *testing.T) {
+	pass := false
+	secure := true
+	services := map[string]*dynamic.Service{
+		"svc": {LoadBalancer: &dynamic.ServersLoadBalancer{
+			Sticky: &dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "old", HTTPOnly: true}},
+		}},
+		"plain":    {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+		"weighted": {Weighted: &dynamic.WeightedRoundRobin{}},
+	}
+
+	overrides := config.ServiceOverrides{
+		LoadBalancers: []config.OverrideLoadBalancer{
+			{
+				Matcher:            "NameRegexp(`.*`)",
+				PassHostHeader:     &pass,
+				ResponseForwarding: &config.OverrideResponseForwarding{FlushInterval: "50ms"},
+				ServersTransport:   "mtls",
+			},
+			{
+				Matcher: "Name(`svc`)",
+				Sticky:  &config.OverrideSticky{Cookie: &config.OverrideCookie{Name: "lb", Secure: &secure, SameSite: "strict"}},
+			},
+			{Matcher: "Name(`plain`)", Sticky: &config.OverrideSticky{}},
+		},
+	}
+
+	OverrideHTTPServices(services, overrides, nil)
+
+	lb := services["svc"].LoadBalancer
+	if lb.PassHostHeader == nil || *lb.PassHostHeader {
+		t.Errorf("expected passHostHeader=false, got %v", lb.PassHostHeader)
+	}
+	if lb.ResponseForwarding == nil || lb.ResponseForwarding.FlushInterval != "50ms" {
+		t.Errorf("unexpected responseForwarding: %+v", lb.ResponseForwarding)
+	}
+	if lb.ServersTransport != "mtls" {
+		t.Errorf("expected serversTransport mtls, got %q", lb.ServersTransport)
+	}
+	want := dynamic.Cookie{Name: "lb", Secure: true, HTTPOnly: true, SameSite: "strict"}
+	if *lb.Sticky.Cookie != want {
+		t.Errorf("cookie=%+v want %+v", *lb.Sticky.Cookie, want)
+	}
+	if plain := services["plain"].LoadBalancer; plain.Sticky == nil || plain.Sticky.Cookie == nil {
+		t.Errorf("expected sticky cookie to be enabled, got %+v", plain.Sticky)
+	}
+	if services["weighted"].LoadBalancer != nil {
+		t.Error("expected weighted service to be left alone")
+	}
+}
+
+func TestOverrideHTTPServices_LoadBalancerRemoveSticky(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"svc": {LoadBalancer: &dynamic.ServersLoadBalancer{Sticky: &dynamic.Sticky{Cookie: &dynamic.Cookie{}}}},
+	}
+	overrides := config.ServiceOverrides{
+		LoadBalancers: []config.OverrideLoadBalancer{{Matcher: "Name(`svc`)", Sticky: &config.OverrideSticky{Remove: true}}},
+	}
+
+	OverrideHTTPServices(services, overrides, nil)
+
+	if services["svc"].LoadBalancer.Sticky != nil {
+		t.Errorf("expected sticky to be removed, got %+v", services["svc"].LoadBalancer.Sticky)
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"strings"
 
 	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
 )
 
-// stripProvider removes the provider postfix after '@' in a given name.
-func stripProvider(name string) string {
-	if name == "" {
+// stripProvider removes the provider postfix after '@' in a given name,
+// unless the postfix is one of the preserved providers.
+func stripProvider(name string, preserved ...string) string {
+	if name == "" || config.IsPreserved(name, preserved) {
 		return name
 	}
 	if i := strings.LastIndex(name, "@"); i >= 0 {
@@ -28,43 +30,36 @@ func StripProviderFromKeys[T any](m map[string]*T) map[string]*T {
 	return out
 }
 
-// StripProviderRefsRouter strips provider postfixes from router references to service and middlewares.
-// Pass middlewares as nil for router types that do not support middlewares (e.g., UDP).
-func StripProviderRefsRouter(service *string, middlewares *[]string) {
-	*service = stripProvider(*service)
-	if middlewares != nil {
-		for i := range *middlewares {
-			(*middlewares)[i] = stripProvider((*middlewares)[i])
-		}
+// stripRefs returns a refFunc stripping provider postfixes from every
+// reference except those to preserved providers.
+func stripRefs(preserved []string) refFunc {
+	return func(_ refKind, ref string) string {
+		return stripProvider(ref, preserved...)
 	}
 }
 
-// StripProvidersHTTP keeps the same API but delegates to the minimal helpers above.
-func StripProvidersHTTP(cfg *dynamic.HTTPConfiguration) {
+// StripProvidersHTTP strips provider postfixes from HTTP keys and from every
+// reference between routers, services and middlewares.
+func StripProvidersHTTP(cfg *dynamic.HTTPConfiguration, preserved ...string) {
 	cfg.Routers = StripProviderFromKeys(cfg.Routers)
 	cfg.Middlewares = StripProviderFromKeys(cfg.Middlewares)
 	cfg.Services = StripProviderFromKeys(cfg.Services)
-	for _, r := range cfg.Routers {
-		StripProviderRefsRouter(&r.Service, &r.Middlewares)
-	}
+	cfg.Models = StripProviderFromKeys(cfg.Models)
+	cfg.ServersTransports = StripProviderFromKeys(cfg.ServersTransports)
+	walkHTTPRefs(cfg, stripRefs(preserved))
 }
 
-// StripProvidersTCP keeps the same API but delegates to the minimal helpers above.
-func StripProvidersTCP(cfg *dynamic.TCPConfiguration) {
+// StripProvidersTCP strips provider postfixes from TCP keys and references.
+func StripProvidersTCP(cfg *dynamic.TCPConfiguration, preserved ...string) {
 	cfg.Routers = StripProviderFromKeys(cfg.Routers)
 	cfg.Middlewares = StripProviderFromKeys(cfg.Middlewares)
 	cfg.Services = StripProviderFromKeys(cfg.Services)
-	for _, r := range cfg.Routers {
-		// TCP routers can have middlewares
-		StripProviderRefsRouter(&r.Service, &r.Middlewares)
-	}
+	walkTCPRefs(cfg, stripRefs(preserved))
 }
 
-// StripProvidersUDP keeps the same API but delegates to the minimal helpers above.
-func StripProvidersUDP(cfg *dynamic.UDPConfiguration) {
+// StripProvidersUDP strips provider postfixes from UDP keys and references.
+func StripProvidersUDP(cfg *dynamic.UDPConfiguration, preserved ...string) {
 	cfg.Routers = StripProviderFromKeys(cfg.Routers)
 	cfg.Services = StripProviderFromKeys(cfg.Services)
-	for _, r := range cfg.Routers {
-		StripProviderRefsRouter(&r.Service, nil)
-	}
+	walkUDPRefs(cfg, stripRefs(preserved))
 }
@@ -1,7 +1,10 @@
 package overrides
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/traefik/genconf/dynamic"
 )
 
 func TestStripProvider(t *testing.T) {
@@ -20,3 +23,53 @@ func TestStripProvider(t *testing.T) {
 		}
 	}
 }
+
+func TestStripProvider_Preserved(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{"api@internal", "api@internal"},
+		{"auth@file", "auth@file"},
+		{"svc@docker", "svc"},
+		{"internal", "internal"},
+	}
+	for _, c := range cases {
+		if got := stripProvider(c.in, "internal", "file"); got != c.out {
+			t.Fatalf("stripProvider(%q)=%q want %q", c.in, got, c.out)
+		}
+	}
+}
+
+func TestStripProvidersHTTP_Preserved(t *testing.T) {
+	cfg := &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"dashboard@internal": {Service: "api@internal", Middlewares: []string{"auth@file", "headers@docker"}},
+		},
+	}
+	StripProvidersHTTP(cfg, "internal", "file")
+
+	r := cfg.Routers["dashboard"]
+	if r == nil {
+		t.Fatalf("router key not stripped: %v", cfg.Routers)
+	}
+	if r.Service != "api@internal" || !reflect.DeepEqual(r.Middlewares, []string{"auth@file", "headers"}) {
+		t.Errorf("unexpected router: %+v", r)
+	}
+}
+
+func TestStripProvidersHTTP_Models(t *testing.T) {
+	cfg := &dynamic.HTTPConfiguration{
+		Models: map[string]*dynamic.Model{
+			"websecure@file": {Middlewares: []string{"headers@file", "auth@internal"}},
+		},
+	}
+	StripProvidersHTTP(cfg, "internal")
+	m := cfg.Models["websecure"]
+	if m == nil {
+		t.Fatalf("expected model key to be stripped: %v", cfg.Models)
+	}
+	if !reflect.DeepEqual(m.Middlewares, []string{"headers", "auth@internal"}) {
+		t.Errorf("middlewares=%v", m.Middlewares)
+	}
+}
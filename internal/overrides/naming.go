@@ -0,0 +1,127 @@
+package overrides
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+// renameFunc returns the new name of a resource.
+type renameFunc func(name string) (string, error)
+
+// newRenamer returns the rename function for naming, or nil when names are
+// kept as they are.
+func newRenamer(naming *config.NamingConfig, providerConfigName string) (renameFunc, error) {
+	switch naming.Strategy {
+	case "", config.NamingStrip:
+		return nil, nil
+	case config.NamingPrefix:
+		return func(name string) (string, error) { return naming.Value + name, nil }, nil
+	case config.NamingSuffix:
+		return func(name string) (string, error) { return name + naming.Value, nil }, nil
+	case config.NamingTemplate:
+		tmpl, err := parseTemplate(naming.Value)
+		if err != nil {
+			return nil, fmt.Errorf("naming template: %w", err)
+		}
+		return func(name string) (string, error) {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, TemplateData{Name: name, ProviderConfigName: providerConfigName}); err != nil {
+				return "", err
+			}
+			if buf.Len() == 0 {
+				return "", fmt.Errorf("rendered empty")
+			}
+			return buf.String(), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported naming strategy %q", naming.Strategy)
+	}
+}
+
+// RenameConfiguration renames the routers, services, middlewares and servers
+// transports of a provider configuration according to naming and rewrites the
+// references between them. References to resources the provider does not
+// define are left unchanged. A resource whose new name fails to render or
+// collides with another one keeps its current name and is returned as an error.
+func RenameConfiguration(cfg *dynamic.Configuration, naming *config.NamingConfig, providerConfigName string) []error {
+	if cfg == nil || naming == nil {
+		return nil
+	}
+	rename, err := newRenamer(naming, providerConfigName)
+	if err != nil {
+		return []error{err}
+	}
+	if rename == nil {
+		return nil
+	}
+	var errs []error
+	if h := cfg.HTTP; h != nil {
+		var services, middlewares, transports map[string]string
+		h.Routers, _, errs = renameKeys(h.Routers, rename, "router", errs)
+		h.Services, services, errs = renameKeys(h.Services, rename, "service", errs)
+		h.Middlewares, middlewares, errs = renameKeys(h.Middlewares, rename, "middleware", errs)
+		h.ServersTransports, transports, errs = renameKeys(h.ServersTransports, rename, "serversTransport", errs)
+		walkHTTPRefs(h, renamedRefs(services, middlewares, transports))
+	}
+	if t := cfg.TCP; t != nil {
+		var services, middlewares map[string]string
+		t.Routers, _, errs = renameKeys(t.Routers, rename, "tcp router", errs)
+		t.Services, services, errs = renameKeys(t.Services, rename, "tcp service", errs)
+		t.Middlewares, middlewares, errs = renameKeys(t.Middlewares, rename, "tcp middleware", errs)
+		walkTCPRefs(t, renamedRefs(services, middlewares, nil))
+	}
+	if u := cfg.UDP; u != nil {
+		var services map[string]string
+		u.Routers, _, errs = renameKeys(u.Routers, rename, "udp router", errs)
+		u.Services, services, errs = renameKeys(u.Services, rename, "udp service", errs)
+		walkUDPRefs(u, renamedRefs(services, nil, nil))
+	}
+	return errs
+}
+
+// renamedRefs returns a refFunc mapping references to their new names.
+func renamedRefs(services, middlewares, transports map[string]string) refFunc {
+	return func(kind refKind, ref string) string {
+		mapping := services
+		switch kind {
+		case refMiddleware:
+			mapping = middlewares
+		case refServersTransport:
+			mapping = transports
+		}
+		if newName, ok := mapping[ref]; ok {
+			return newName
+		}
+		return ref
+	}
+}
+
+// renameKeys returns m with its keys renamed and the mapping from old to new
+// names. Keys are processed in sorted order so collisions resolve the same
+// way on every poll.
+func renameKeys[T any](m map[string]*T, rename renameFunc, kind string, errs []error) (map[string]*T, map[string]string, []error) {
+	if len(m) == 0 {
+		return m, nil, errs
+	}
+	names := sortedKeys(m)
+	out := make(map[string]*T, len(m))
+	mapping := make(map[string]string, len(m))
+	for _, name := range names {
+		newName, err := rename(name)
+		if err == nil {
+			if _, taken := out[newName]; taken {
+				err = fmt.Errorf("%q is already taken", newName)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: rename: %w", kind, name, err))
+			newName = name
+		}
+		out[newName] = m[name]
+		mapping[name] = newName
+	}
+	return out, mapping, errs
+}
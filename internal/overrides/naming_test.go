@@ -0,0 +1,156 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestRenameConfiguration_RouterRefs(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers:     map[string]*dynamic.Router{"api": {Service: "api", Middlewares: []string{"auth", "auth@file"}}},
+			Services:    map[string]*dynamic.Service{"api": {}},
+			Middlewares: map[string]*dynamic.Middleware{"auth": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:     map[string]*dynamic.TCPRouter{"db": {Service: "db", Middlewares: []string{"allow"}}},
+			Services:    map[string]*dynamic.TCPService{"db": {}},
+			Middlewares: map[string]*dynamic.TCPMiddleware{"allow": {}},
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  map[string]*dynamic.UDPRouter{"dns": {Service: "dns"}},
+			Services: map[string]*dynamic.UDPService{"dns": {}},
+		},
+	}
+	if errs := RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingPrefix, Value: "east-"}, "east"); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if r := cfg.HTTP.Routers["east-api"]; r == nil || r.Service != "east-api" || !reflect.DeepEqual(r.Middlewares, []string{"east-auth", "auth@file"}) {
+		t.Errorf("unexpected router: %+v", r)
+	}
+	if _, ok := cfg.HTTP.Middlewares["east-auth"]; !ok {
+		t.Error("middleware not renamed")
+	}
+	if r := cfg.TCP.Routers["east-db"]; r == nil || r.Service != "east-db" || !reflect.DeepEqual(r.Middlewares, []string{"east-allow"}) {
+		t.Errorf("unexpected tcp router: %+v", r)
+	}
+	if r := cfg.UDP.Routers["east-dns"]; r == nil || r.Service != "east-dns" {
+		t.Errorf("unexpected udp router: %+v", r)
+	}
+}
+
+func TestRenameConfiguration_ServiceChildren(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{Services: map[string]*dynamic.Service{
+			"api":     {},
+			"api-v2":  {},
+			"canary":  {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api"}, {Name: "api-v2"}}}},
+			"shadow":  {Mirroring: &dynamic.Mirroring{Service: "api", Mirrors: []dynamic.MirrorService{{Name: "api-v2"}}}},
+			"standby": {Failover: &dynamic.Failover{Service: "api", Fallback: "external@file"}},
+		}},
+		TCP: &dynamic.TCPConfiguration{Services: map[string]*dynamic.TCPService{
+			"db":     {},
+			"db-wrr": {Weighted: &dynamic.TCPWeightedRoundRobin{Services: []dynamic.TCPWRRService{{Name: "db"}}}},
+		}},
+		UDP: &dynamic.UDPConfiguration{Services: map[string]*dynamic.UDPService{
+			"dns":     {},
+			"dns-wrr": {Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns"}}}},
+		}},
+	}
+	RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingSuffix, Value: "-east"}, "east")
+
+	h := cfg.HTTP
+	if got := h.Services["canary-east"].Weighted.Services; got[0].Name != "api-east" || got[1].Name != "api-v2-east" {
+		t.Errorf("weighted=%+v", got)
+	}
+	if m := h.Services["shadow-east"].Mirroring; m.Service != "api-east" || m.Mirrors[0].Name != "api-v2-east" {
+		t.Errorf("mirroring=%+v", m)
+	}
+	if f := h.Services["standby-east"].Failover; f.Service != "api-east" || f.Fallback != "external@file" {
+		t.Errorf("failover=%+v", f)
+	}
+	if got := cfg.TCP.Services["db-wrr-east"].Weighted.Services[0].Name; got != "db-east" {
+		t.Errorf("tcp weighted=%q", got)
+	}
+	if got := cfg.UDP.Services["dns-wrr-east"].Weighted.Services[0].Name; got != "dns-east" {
+		t.Errorf("udp weighted=%q", got)
+	}
+}
+
+func TestRenameConfiguration_MiddlewareRefs(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Services: map[string]*dynamic.Service{"pages": {}},
+		Middlewares: map[string]*dynamic.Middleware{
+			"secured": {Chain: &dynamic.Chain{Middlewares: []string{"errors", "auth@file"}}},
+			"errors":  {Errors: &dynamic.ErrorPage{Service: "pages"}},
+		},
+	}}
+	RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingTemplate, Value: "{{ .ProviderConfigName | upper }}_{{ .Name }}"}, "east")
+
+	if got := cfg.HTTP.Middlewares["EAST_secured"].Chain.Middlewares; !reflect.DeepEqual(got, []string{"EAST_errors", "auth@file"}) {
+		t.Errorf("chain=%v", got)
+	}
+	if got := cfg.HTTP.Middlewares["EAST_errors"].Errors.Service; got != "EAST_pages" {
+		t.Errorf("errors service=%q", got)
+	}
+}
+
+func TestRenameConfiguration_Transports(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Services:          map[string]*dynamic.Service{"api": {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "tunnel"}}},
+		ServersTransports: map[string]*dynamic.ServersTransport{"tunnel": {}},
+	}}
+	RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingPrefix, Value: "east-"}, "east")
+
+	if got := cfg.HTTP.Services["east-api"].LoadBalancer.ServersTransport; got != "east-tunnel" {
+		t.Errorf("serversTransport=%q", got)
+	}
+	if _, ok := cfg.HTTP.ServersTransports["east-tunnel"]; !ok {
+		t.Error("serversTransport not renamed")
+	}
+}
+
+func TestRenameConfiguration_Strip(t *testing.T) {
+	newConfig := func() *dynamic.Configuration {
+		return &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+			Routers:  map[string]*dynamic.Router{"api": {Service: "api", Middlewares: []string{"auth@file"}}},
+			Services: map[string]*dynamic.Service{"api": {}},
+		}}
+	}
+	for _, naming := range []*config.NamingConfig{nil, {}, {Strategy: config.NamingStrip}} {
+		cfg := newConfig()
+		if errs := RenameConfiguration(cfg, naming, "east"); len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if !reflect.DeepEqual(cfg, newConfig()) {
+			t.Errorf("configuration changed for %+v", naming)
+		}
+	}
+}
+
+func TestRenameConfiguration_Errors(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{"a": {Service: "a"}, "b": {Service: "b"}},
+	}}
+	errs := RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingTemplate, Value: "fixed"}, "east")
+	if len(errs) != 1 {
+		t.Fatalf("expected one collision error, got %v", errs)
+	}
+	if _, ok := cfg.HTTP.Routers["fixed"]; !ok {
+		t.Error("first router not renamed")
+	}
+	if _, ok := cfg.HTTP.Routers["b"]; !ok {
+		t.Error("colliding router did not keep its name")
+	}
+
+	if errs := RenameConfiguration(cfg, &config.NamingConfig{Strategy: config.NamingTemplate, Value: "{{ .Name"}, "east"); len(errs) != 1 {
+		t.Errorf("expected template error, got %v", errs)
+	}
+	if errs := RenameConfiguration(cfg, &config.NamingConfig{Strategy: "hash"}, "east"); len(errs) != 1 {
+		t.Errorf("expected strategy error, got %v", errs)
+	}
+}
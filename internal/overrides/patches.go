@@ -0,0 +1,145 @@
+package overrides
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/jsonpatch"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// applyPatches applies each patch to the resources returned by sel for the patch matcher.
+// A resource that fails to patch is left untouched and the failure is returned.
+func applyPatches[T any](items map[string]*T, patches []config.Patch, kind string, sel func(matcher string) map[string]*T) []error {
+	var errs []error
+	for i, p := range patches {
+		for name, item := range sel(p.Matcher) {
+			patched, err := patchResource(item, p)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: patch %d: %w", kind, name, i, err))
+				continue
+			}
+			items[name] = patched
+		}
+	}
+	return errs
+}
+
+// patchResource applies p to the JSON form of v and decodes the result into a new value.
+func patchResource[T any](v *T, p config.Patch) (*T, error) {
+	doc, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if p.Merge != nil {
+		merge, err := toJSONValue(p.Merge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge patch: %w", err)
+		}
+		doc = jsonpatch.MergePatch(doc, merge)
+	}
+	if len(p.JSON) > 0 {
+		ops := make([]jsonpatch.Operation, 0, len(p.JSON))
+		for _, op := range p.JSON {
+			value, err := toJSONValue(op.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s %s: %w", op.Op, op.Path, err)
+			}
+			ops = append(ops, jsonpatch.Operation{Op: op.Op, Path: op.Path, From: op.From, Value: value})
+		}
+		if doc, err = jsonpatch.Apply(doc, ops); err != nil {
+			return nil, err
+		}
+	}
+	return decodeResource[T](doc)
+}
+
+// decodeResource decodes the JSON form of a resource, rejecting unknown fields.
+func decodeResource[T any](doc interface{}) (*T, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// toJSONValue normalizes a config-decoded value to the shapes produced by encoding/json.
+func toJSONValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PatchHTTPRouters applies patches to matching HTTP routers.
+func PatchHTTPRouters(routers map[string]*dynamic.Router, patches []config.Patch) []error {
+	return applyPatches(routers, patches, "router", func(m string) map[string]*dynamic.Router {
+		return matchers.HTTPRouters(routers, &config.RoutersConfig{Matcher: m, DiscoverPriority: true}, "")
+	})
+}
+
+// PatchHTTPServices applies patches to matching HTTP services.
+func PatchHTTPServices(services map[string]*dynamic.Service, patches []config.Patch) []error {
+	return applyPatches(services, patches, "service", func(m string) map[string]*dynamic.Service {
+		return matchers.HTTPServices(services, &config.ServicesConfig{Matcher: m}, "")
+	})
+}
+
+// PatchHTTPMiddlewares applies patches to matching HTTP middlewares.
+func PatchHTTPMiddlewares(middlewares map[string]*dynamic.Middleware, patches []config.Patch) []error {
+	return applyPatches(middlewares, patches, "middleware", func(m string) map[string]*dynamic.Middleware {
+		return matchers.HTTPMiddlewares(middlewares, &config.MiddlewaresConfig{Matcher: m}, "")
+	})
+}
+
+// PatchTCPRouters applies patches to matching TCP routers.
+func PatchTCPRouters(routers map[string]*dynamic.TCPRouter, patches []config.Patch) []error {
+	return applyPatches(routers, patches, "tcp router", func(m string) map[string]*dynamic.TCPRouter {
+		return matchers.TCPRouters(routers, &config.RoutersConfig{Matcher: m}, "")
+	})
+}
+
+// PatchTCPServices applies patches to matching TCP services.
+func PatchTCPServices(services map[string]*dynamic.TCPService, patches []config.Patch) []error {
+	return applyPatches(services, patches, "tcp service", func(m string) map[string]*dynamic.TCPService {
+		return matchers.TCPServices(services, &config.ServicesConfig{Matcher: m}, "")
+	})
+}
+
+// PatchTCPMiddlewares applies patches to matching TCP middlewares.
+func PatchTCPMiddlewares(middlewares map[string]*dynamic.TCPMiddleware, patches []config.Patch) []error {
+	return applyPatches(middlewares, patches, "tcp middleware", func(m string) map[string]*dynamic.TCPMiddleware {
+		return matchers.TCPMiddlewares(middlewares, &config.MiddlewaresConfig{Matcher: m}, "")
+	})
+}
+
+// PatchUDPRouters applies patches to matching UDP routers.
+func PatchUDPRouters(routers map[string]*dynamic.UDPRouter, patches []config.Patch) []error {
+	return applyPatches(routers, patches, "udp router", func(m string) map[string]*dynamic.UDPRouter {
+		return matchers.UDPRouters(routers, &config.UDPRoutersConfig{Matcher: m}, "")
+	})
+}
+
+// PatchUDPServices applies patches to matching UDP services.
+func PatchUDPServices(services map[string]*dynamic.UDPService, patches []config.Patch) []error {
+	return applyPatches(services, patches, "udp service", func(m string) map[string]*dynamic.UDPService {
+		return matchers.UDPServices(services, &config.UDPServicesConfig{Matcher: m}, "")
+	})
+}
@@ -0,0 +1,98 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestPatchHTTPRouters_MergePatch(t *testing.T) {
+	routers := map[string]*dynamic.Router{
+		"api":   {Rule: "Host(`api`)", Service: "api", Priority: 7, TLS: &dynamic.RouterTLSConfig{Options: "strict"}},
+		"other": {Rule: "Host(`other`)", Service: "other"},
+	}
+	patches := []config.Patch{{
+		Matcher: "Name(`api`)",
+		Merge: map[string]interface{}{
+			"tls":         nil,
+			"middlewares": []interface{}{"auth"},
+		},
+	}}
+
+	if errs := PatchHTTPRouters(routers, patches); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	r := routers["api"]
+	if r.TLS != nil {
+		t.Errorf("expected tls to be removed, got %+v", r.TLS)
+	}
+	if !reflect.DeepEqual(r.Middlewares, []string{"auth"}) {
+		t.Errorf("middlewares=%v want [auth]", r.Middlewares)
+	}
+	if r.Priority != 7 || r.Rule != "Host(`api`)" {
+		t.Errorf("unexpected change to untouched fields: %+v", r)
+	}
+	if routers["other"].Middlewares != nil {
+		t.Errorf("unmatched router was patched: %+v", routers["other"])
+	}
+}
+
+func TestPatchHTTPServices_JSONPatch(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"svc": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://a"}}}},
+	}
+	patches := []config.Patch{{
+		Matcher: "Name(`svc`)",
+		JSON: []config.PatchOperation{
+			{Op: "add", Path: "/loadBalancer/servers/-", Value: map[string]interface{}{"url": "http://b"}},
+			{Op: "add", Path: "/loadBalancer/serversTransport", Value: "mtls"},
+		},
+	}}
+
+	if errs := PatchHTTPServices(services, patches); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	lb := services["svc"].LoadBalancer
+	if len(lb.Servers) != 2 || lb.Servers[1].URL != "http://b" || lb.ServersTransport != "mtls" {
+		t.Fatalf("unexpected load balancer: %+v", lb)
+	}
+}
+
+func TestPatchHTTPMiddlewares_ErrorLeavesResourceUntouched(t *testing.T) {
+	middlewares := map[string]*dynamic.Middleware{
+		"strip": {StripPrefix: &dynamic.StripPrefix{Prefixes: []string{"/api"}}},
+	}
+	patches := []config.Patch{
+		{Matcher: "Name(`strip`)", JSON: []config.PatchOperation{{Op: "remove", Path: "/missing"}}},
+		{Matcher: "Name(`strip`)", Merge: map[string]interface{}{"notAField": true}},
+	}
+
+	errs := PatchHTTPMiddlewares(middlewares, patches)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if got := middlewares["strip"].StripPrefix.Prefixes; !reflect.DeepEqual(got, []string{"/api"}) {
+		t.Fatalf("middleware modified despite errors: %v", got)
+	}
+}
+
+func TestPatchTCPAndUDP(t *testing.T) {
+	tcpRouters := map[string]*dynamic.TCPRouter{"db": {Rule: "HostSNI(`*`)", Service: "db"}}
+	if errs := PatchTCPRouters(tcpRouters, []config.Patch{{Merge: map[string]interface{}{"entryPoints": []interface{}{"postgres"}}}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if !reflect.DeepEqual(tcpRouters["db"].EntryPoints, []string{"postgres"}) {
+		t.Errorf("tcp entrypoints=%v", tcpRouters["db"].EntryPoints)
+	}
+
+	udpServices := map[string]*dynamic.UDPService{"dns": {LoadBalancer: &dynamic.UDPServersLoadBalancer{Servers: []dynamic.UDPServer{{Address: "a:53"}}}}}
+	patches := []config.Patch{{Matcher: "Name(`dns`)", JSON: []config.PatchOperation{{Op: "replace", Path: "/loadBalancer/servers/0/address", Value: "b:53"}}}}
+	if errs := PatchUDPServices(udpServices, patches); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := udpServices["dns"].LoadBalancer.Servers[0].Address; got != "b:53" {
+		t.Errorf("udp server address=%q want b:53", got)
+	}
+}
@@ -0,0 +1,114 @@
+package overrides
+
+import (
+	"fmt"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// Prune removes the services, middlewares and servers transports of cfg that
+// no router or HTTP model uses, directly or through other resources. Resources whose name
+// matches a keep matcher are kept along with what they use. Keep matchers are
+// validated when the plugin is created; nothing is pruned when one does not
+// compile.
+func Prune(cfg *dynamic.Configuration, p *config.PruneConfig) []error {
+	if cfg == nil || p == nil {
+		return nil
+	}
+	keep := map[refKind]*rules.Program{}
+	for kind, matcher := range map[refKind]string{
+		refService:          p.KeepServices,
+		refMiddleware:       p.KeepMiddlewares,
+		refServersTransport: p.KeepServersTransports,
+	} {
+		if matcher == "" {
+			continue
+		}
+		prog, err := rules.Compile(matcher)
+		if err != nil {
+			return []error{fmt.Errorf("prune: keep matcher %q: %w", matcher, err)}
+		}
+		keep[kind] = prog
+	}
+
+	if h := cfg.HTTP; h != nil {
+		roots := depWalkers(h.Routers, walkHTTPRouterRefs)
+		for name, walk := range depWalkers(h.Models, walkHTTPModelRefs) {
+			roots["model "+name] = walk
+		}
+		used := reachable(roots, map[refKind]map[string]depWalker{
+			refService:          depWalkers(h.Services, walkHTTPServiceRefs),
+			refMiddleware:       depWalkers(h.Middlewares, walkHTTPMiddlewareRefs),
+			refServersTransport: depWalkers(h.ServersTransports, func(*dynamic.ServersTransport, refFunc) {}),
+		}, keep)
+		pruneKeys(h.Services, used[refService])
+		pruneKeys(h.Middlewares, used[refMiddleware])
+		pruneKeys(h.ServersTransports, used[refServersTransport])
+	}
+	if t := cfg.TCP; t != nil {
+		used := reachable(depWalkers(t.Routers, walkTCPRouterRefs), map[refKind]map[string]depWalker{
+			refService:    depWalkers(t.Services, walkTCPServiceRefs),
+			refMiddleware: depWalkers(t.Middlewares, func(*dynamic.TCPMiddleware, refFunc) {}),
+		}, keep)
+		pruneKeys(t.Services, used[refService])
+		pruneKeys(t.Middlewares, used[refMiddleware])
+	}
+	if u := cfg.UDP; u != nil {
+		used := reachable(depWalkers(u.Routers, func(r *dynamic.UDPRouter, fn refFunc) {
+			r.Service = walkRef(fn, refService, r.Service)
+		}), map[refKind]map[string]depWalker{
+			refService: depWalkers(u.Services, walkUDPServiceRefs),
+		}, keep)
+		pruneKeys(u.Services, used[refService])
+	}
+	return nil
+}
+
+// reachable returns, per kind, the names of the resources in pools used by
+// roots or by resources matched by keep, transitively.
+func reachable(roots map[string]depWalker, pools map[refKind]map[string]depWalker, keep map[refKind]*rules.Program) map[refKind]map[string]bool {
+	used := map[refKind]map[string]bool{}
+	queue := make([]depWalker, 0, len(roots))
+	mark := func(kind refKind, name string) {
+		walk, ok := pools[kind][name]
+		if !ok || used[kind][name] {
+			return
+		}
+		if used[kind] == nil {
+			used[kind] = map[string]bool{}
+		}
+		used[kind][name] = true
+		queue = append(queue, walk)
+	}
+	for _, walk := range roots {
+		queue = append(queue, walk)
+	}
+	for kind, pool := range pools {
+		if prog := keep[kind]; prog != nil {
+			for name := range pool {
+				if prog.Match(rules.Context{Name: name, Provider: providerOf(name)}) {
+					mark(kind, name)
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		walk := queue[0]
+		queue = queue[1:]
+		walk(func(kind refKind, ref string) string {
+			mark(kind, ref)
+			return ref
+		})
+	}
+	return used
+}
+
+func pruneKeys[T any](m map[string]*T, used map[string]bool) {
+	for name := range m {
+		if !used[name] {
+			delete(m, name)
+		}
+	}
+}
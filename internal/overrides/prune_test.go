@@ -0,0 +1,141 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestPrune_WeightedChain(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{"api": {Service: "canary"}},
+		Services: map[string]*dynamic.Service{
+			"canary": {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api-v1"}}}},
+			"api-v1": {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "tunnel"}},
+			"unused": {},
+		},
+		ServersTransports: map[string]*dynamic.ServersTransport{"tunnel": {}, "unused": {}},
+	}}
+	if errs := Prune(cfg, &config.PruneConfig{}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if got := sortedKeys(cfg.HTTP.Services); !reflect.DeepEqual(got, []string{"api-v1", "canary"}) {
+		t.Errorf("services=%v", got)
+	}
+	if got := sortedKeys(cfg.HTTP.ServersTransports); !reflect.DeepEqual(got, []string{"tunnel"}) {
+		t.Errorf("serversTransports=%v", got)
+	}
+	if len(cfg.HTTP.Routers) != 1 {
+		t.Error("routers must never be pruned")
+	}
+}
+
+func TestPrune_ErrorsService(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{"api": {Service: "api", Middlewares: []string{"secured", "auth@internal"}}},
+		Services: map[string]*dynamic.Service{
+			"api":        {},
+			"error-page": {},
+			"unused":     {},
+		},
+		Middlewares: map[string]*dynamic.Middleware{
+			"secured": {Chain: &dynamic.Chain{Middlewares: []string{"errors"}}},
+			"errors":  {Errors: &dynamic.ErrorPage{Service: "error-page"}},
+			"unused":  {},
+		},
+	}}
+	Prune(cfg, &config.PruneConfig{})
+
+	if got := sortedKeys(cfg.HTTP.Services); !reflect.DeepEqual(got, []string{"api", "error-page"}) {
+		t.Errorf("services=%v", got)
+	}
+	if got := sortedKeys(cfg.HTTP.Middlewares); !reflect.DeepEqual(got, []string{"errors", "secured"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+}
+
+func TestPrune_ModelRoots(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Middlewares: map[string]*dynamic.Middleware{"headers": {}, "unused": {}},
+		Models:      map[string]*dynamic.Model{"websecure": {Middlewares: []string{"headers"}}},
+	}}
+	Prune(cfg, &config.PruneConfig{})
+
+	if got := sortedKeys(cfg.HTTP.Middlewares); !reflect.DeepEqual(got, []string{"headers"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+}
+
+func TestPrune_KeepMatchers(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Services: map[string]*dynamic.Service{
+				"exported": {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "exported-transport"}},
+				"unused":   {},
+			},
+			Middlewares:       map[string]*dynamic.Middleware{"shared-auth": {}, "unused": {}},
+			ServersTransports: map[string]*dynamic.ServersTransport{"exported-transport": {}, "mtls": {}, "unused": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Services: map[string]*dynamic.TCPService{"exported": {}, "unused": {}},
+		},
+	}
+	Prune(cfg, &config.PruneConfig{
+		KeepServices:          "Name(`exported`)",
+		KeepMiddlewares:       "NameRegexp(`^shared-`)",
+		KeepServersTransports: "Name(`mtls`)",
+	})
+
+	if got := sortedKeys(cfg.HTTP.Services); !reflect.DeepEqual(got, []string{"exported"}) {
+		t.Errorf("services=%v", got)
+	}
+	if got := sortedKeys(cfg.HTTP.Middlewares); !reflect.DeepEqual(got, []string{"shared-auth"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+	if got := sortedKeys(cfg.HTTP.ServersTransports); !reflect.DeepEqual(got, []string{"exported-transport", "mtls"}) {
+		t.Errorf("serversTransports=%v", got)
+	}
+	if got := sortedKeys(cfg.TCP.Services); !reflect.DeepEqual(got, []string{"exported"}) {
+		t.Errorf("tcp services=%v", got)
+	}
+}
+
+func TestPrune_TCPAndUDP(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		TCP: &dynamic.TCPConfiguration{
+			Routers:     map[string]*dynamic.TCPRouter{"db": {Service: "db", Middlewares: []string{"allow"}}},
+			Services:    map[string]*dynamic.TCPService{"db": {}, "unused": {}},
+			Middlewares: map[string]*dynamic.TCPMiddleware{"allow": {}, "unused": {}},
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  map[string]*dynamic.UDPRouter{"dns": {Service: "dns"}},
+			Services: map[string]*dynamic.UDPService{"dns": {}, "unused": {}},
+		},
+	}
+	Prune(cfg, &config.PruneConfig{})
+
+	if got := sortedKeys(cfg.TCP.Services); !reflect.DeepEqual(got, []string{"db"}) {
+		t.Errorf("tcp services=%v", got)
+	}
+	if got := sortedKeys(cfg.TCP.Middlewares); !reflect.DeepEqual(got, []string{"allow"}) {
+		t.Errorf("tcp middlewares=%v", got)
+	}
+	if got := sortedKeys(cfg.UDP.Services); !reflect.DeepEqual(got, []string{"dns"}) {
+		t.Errorf("udp services=%v", got)
+	}
+}
+
+func TestPrune_DisabledOrInvalid(t *testing.T) {
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Services: map[string]*dynamic.Service{"unused": {}},
+	}}
+	if errs := Prune(cfg, nil); len(errs) != 0 || len(cfg.HTTP.Services) != 1 {
+		t.Error("nil config must not prune")
+	}
+	if errs := Prune(cfg, &config.PruneConfig{KeepMiddlewares: "Name(`x`"}); len(errs) != 1 || len(cfg.HTTP.Services) != 1 {
+		t.Errorf("invalid keep matcher must not prune, errs=%v", errs)
+	}
+}
@@ -0,0 +1,96 @@
+package overrides
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// AddHTTPSRedirects adds, for each router with TLS that is not excluded and
+// not already on an insecure entrypoint, a router on the insecure entrypoints
+// with the same rule and a redirectScheme middleware. Generated router names
+// get a numeric suffix when the preferred name is taken, and so does the
+// middleware when its name is used by a middleware that is not a
+// redirectScheme.
+func AddHTTPSRedirects(httpConfig *dynamic.HTTPConfiguration, rc *config.HTTPSRedirectConfig) {
+	if rc == nil || len(httpConfig.Routers) == 0 {
+		return
+	}
+	entrypoints := rc.Entrypoints
+	if len(entrypoints) == 0 {
+		entrypoints = []string{config.DefaultRedirectEntrypoint}
+	}
+	middleware := rc.Middleware
+	if middleware == "" {
+		middleware = config.DefaultRedirectMiddleware
+	}
+	if existing, ok := httpConfig.Middlewares[middleware]; ok && existing.RedirectScheme == nil {
+		middleware = freeName(httpConfig.Middlewares, middleware)
+	}
+	suffix := rc.Suffix
+	if suffix == "" {
+		suffix = config.DefaultRedirectSuffix
+	}
+	excluded := map[string]*dynamic.Router{}
+	if rc.Exclude != "" {
+		excluded = matchers.HTTPRouters(httpConfig.Routers, &config.RoutersConfig{Matcher: rc.Exclude, DiscoverPriority: true}, "")
+	}
+
+	names := make([]string, 0, len(httpConfig.Routers))
+	for name, r := range httpConfig.Routers {
+		if _, ok := excluded[name]; ok || r.TLS == nil || sharesEntrypoint(r.EntryPoints, entrypoints) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := httpConfig.Routers[name]
+		httpConfig.Routers[freeName(httpConfig.Routers, name+suffix)] = &dynamic.Router{
+			EntryPoints: append([]string(nil), entrypoints...),
+			Middlewares: []string{middleware},
+			Service:     r.Service,
+			Rule:        r.Rule,
+			Priority:    r.Priority,
+		}
+	}
+
+	if httpConfig.Middlewares == nil {
+		httpConfig.Middlewares = make(map[string]*dynamic.Middleware)
+	}
+	if _, exists := httpConfig.Middlewares[middleware]; !exists {
+		httpConfig.Middlewares[middleware] = &dynamic.Middleware{
+			RedirectScheme: &dynamic.RedirectScheme{Scheme: "https", Port: rc.Port, Permanent: rc.Permanent},
+		}
+	}
+}
+
+func sharesEntrypoint(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// freeName returns name, or name with the first numeric suffix not used in m.
+func freeName[T any](m map[string]*T, name string) string {
+	if _, taken := m[name]; !taken {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, taken := m[candidate]; !taken {
+			return candidate
+		}
+	}
+}
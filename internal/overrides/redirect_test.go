@@ -0,0 +1,82 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestAddHTTPSRedirects(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"api":          {Rule: "Host(`api`)", Service: "api", EntryPoints: []string{"websecure"}, Priority: 10, TLS: &dynamic.RouterTLSConfig{}},
+			"api-redirect": {Rule: "Host(`taken`)", Service: "other"},
+			"plain":        {Rule: "Host(`plain`)", Service: "plain", EntryPoints: []string{"web"}},
+			"both":         {Rule: "Host(`both`)", Service: "both", EntryPoints: []string{"web", "websecure"}, TLS: &dynamic.RouterTLSConfig{}},
+			"health":       {Rule: "Path(`/health`)", Service: "health", TLS: &dynamic.RouterTLSConfig{}},
+		},
+	}
+
+	AddHTTPSRedirects(httpConfig, &config.HTTPSRedirectConfig{Permanent: true, Exclude: "Name(`health`)"})
+
+	want := &dynamic.Router{Rule: "Host(`api`)", Service: "api", EntryPoints: []string{"web"}, Middlewares: []string{"redirect-to-https"}, Priority: 10}
+	if got := httpConfig.Routers["api-redirect-2"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+	if httpConfig.Routers["api-redirect"].Rule != "Host(`taken`)" {
+		t.Error("existing router was overwritten")
+	}
+	for _, name := range []string{"plain-redirect", "both-redirect", "health-redirect"} {
+		if _, ok := httpConfig.Routers[name]; ok {
+			t.Errorf("unexpected redirect router %q", name)
+		}
+	}
+	mw := httpConfig.Middlewares["redirect-to-https"]
+	if mw == nil || mw.RedirectScheme == nil || *mw.RedirectScheme != (dynamic.RedirectScheme{Scheme: "https", Permanent: true}) {
+		t.Errorf("unexpected middleware: %+v", mw)
+	}
+}
+
+func TestAddHTTPSRedirects_CustomNamesAndExistingMiddleware(t *testing.T) {
+	existing := &dynamic.Middleware{RedirectScheme: &dynamic.RedirectScheme{Scheme: "https", Port: "8443"}}
+	httpConfig := &dynamic.HTTPConfiguration{
+		Routers:     map[string]*dynamic.Router{"shop": {Rule: "Host(`shop`)", Service: "shop", TLS: &dynamic.RouterTLSConfig{}}},
+		Middlewares: map[string]*dynamic.Middleware{"to-https": existing},
+	}
+
+	AddHTTPSRedirects(httpConfig, &config.HTTPSRedirectConfig{Entrypoints: []string{"http", "http-alt"}, Middleware: "to-https", Suffix: "-http"})
+
+	r := httpConfig.Routers["shop-http"]
+	if r == nil || !reflect.DeepEqual(r.EntryPoints, []string{"http", "http-alt"}) || !reflect.DeepEqual(r.Middlewares, []string{"to-https"}) {
+		t.Fatalf("unexpected router: %+v", r)
+	}
+	if httpConfig.Middlewares["to-https"] != existing {
+		t.Error("existing middleware was replaced")
+	}
+
+	AddHTTPSRedirects(httpConfig, nil)
+	AddHTTPSRedirects(&dynamic.HTTPConfiguration{}, &config.HTTPSRedirectConfig{})
+}
+
+func TestAddHTTPSRedirects_MiddlewareNameTaken(t *testing.T) {
+	auth := &dynamic.Middleware{BasicAuth: &dynamic.BasicAuth{Users: []string{"u:p"}}}
+	httpConfig := &dynamic.HTTPConfiguration{
+		Routers:     map[string]*dynamic.Router{"shop": {Rule: "Host(`shop`)", Service: "shop", TLS: &dynamic.RouterTLSConfig{}}},
+		Middlewares: map[string]*dynamic.Middleware{config.DefaultRedirectMiddleware: auth},
+	}
+
+	AddHTTPSRedirects(httpConfig, &config.HTTPSRedirectConfig{})
+
+	if httpConfig.Middlewares[config.DefaultRedirectMiddleware] != auth {
+		t.Error("existing middleware was replaced")
+	}
+	name := config.DefaultRedirectMiddleware + "-2"
+	if m := httpConfig.Middlewares[name]; m == nil || m.RedirectScheme == nil {
+		t.Fatalf("expected redirectScheme middleware %q, got %+v", name, m)
+	}
+	if r := httpConfig.Routers["shop-redirect"]; r == nil || !reflect.DeepEqual(r.Middlewares, []string{name}) {
+		t.Fatalf("unexpected router: %+v", r)
+	}
+}
@@ -0,0 +1,135 @@
+package overrides
+
+import "github.com/traefik/genconf/dynamic"
+
+// refKind identifies the kind of resource a reference points at.
+type refKind int
+
+const (
+	refService refKind = iota
+	refMiddleware
+	refServersTransport
+)
+
+// refFunc returns the replacement for a reference of the given kind.
+type refFunc func(kind refKind, ref string) string
+
+// walkHTTPRefs calls fn for every reference held by HTTP routers, services,
+// middlewares and models and stores the returned value in its place.
+func walkHTTPRefs(cfg *dynamic.HTTPConfiguration, fn refFunc) {
+	if cfg == nil {
+		return
+	}
+	for _, r := range cfg.Routers {
+		walkHTTPRouterRefs(r, fn)
+	}
+	for _, s := range cfg.Services {
+		walkHTTPServiceRefs(s, fn)
+	}
+	for _, m := range cfg.Middlewares {
+		walkHTTPMiddlewareRefs(m, fn)
+	}
+	for _, m := range cfg.Models {
+		walkHTTPModelRefs(m, fn)
+	}
+}
+
+func walkHTTPModelRefs(m *dynamic.Model, fn refFunc) {
+	walkRefList(fn, refMiddleware, m.Middlewares)
+}
+
+func walkHTTPRouterRefs(r *dynamic.Router, fn refFunc) {
+	r.Service = walkRef(fn, refService, r.Service)
+	walkRefList(fn, refMiddleware, r.Middlewares)
+}
+
+func walkHTTPMiddlewareRefs(m *dynamic.Middleware, fn refFunc) {
+	if m.Chain != nil {
+		walkRefList(fn, refMiddleware, m.Chain.Middlewares)
+	}
+	if m.Errors != nil {
+		m.Errors.Service = walkRef(fn, refService, m.Errors.Service)
+	}
+}
+
+func walkHTTPServiceRefs(s *dynamic.Service, fn refFunc) {
+	if s.LoadBalancer != nil {
+		s.LoadBalancer.ServersTransport = walkRef(fn, refServersTransport, s.LoadBalancer.ServersTransport)
+	}
+	if s.Weighted != nil {
+		for i := range s.Weighted.Services {
+			s.Weighted.Services[i].Name = walkRef(fn, refService, s.Weighted.Services[i].Name)
+		}
+	}
+	if s.Mirroring != nil {
+		s.Mirroring.Service = walkRef(fn, refService, s.Mirroring.Service)
+		for i := range s.Mirroring.Mirrors {
+			s.Mirroring.Mirrors[i].Name = walkRef(fn, refService, s.Mirroring.Mirrors[i].Name)
+		}
+	}
+	if s.Failover != nil {
+		s.Failover.Service = walkRef(fn, refService, s.Failover.Service)
+		s.Failover.Fallback = walkRef(fn, refService, s.Failover.Fallback)
+	}
+}
+
+// walkTCPRefs calls fn for every reference held by TCP routers and services.
+func walkTCPRefs(cfg *dynamic.TCPConfiguration, fn refFunc) {
+	if cfg == nil {
+		return
+	}
+	for _, r := range cfg.Routers {
+		walkTCPRouterRefs(r, fn)
+	}
+	for _, s := range cfg.Services {
+		walkTCPServiceRefs(s, fn)
+	}
+}
+
+func walkTCPRouterRefs(r *dynamic.TCPRouter, fn refFunc) {
+	r.Service = walkRef(fn, refService, r.Service)
+	walkRefList(fn, refMiddleware, r.Middlewares)
+}
+
+func walkTCPServiceRefs(s *dynamic.TCPService, fn refFunc) {
+	if s.Weighted != nil {
+		for i := range s.Weighted.Services {
+			s.Weighted.Services[i].Name = walkRef(fn, refService, s.Weighted.Services[i].Name)
+		}
+	}
+}
+
+// walkUDPRefs calls fn for every reference held by UDP routers and services.
+func walkUDPRefs(cfg *dynamic.UDPConfiguration, fn refFunc) {
+	if cfg == nil {
+		return
+	}
+	for _, r := range cfg.Routers {
+		r.Service = walkRef(fn, refService, r.Service)
+	}
+	for _, s := range cfg.Services {
+		walkUDPServiceRefs(s, fn)
+	}
+}
+
+func walkUDPServiceRefs(s *dynamic.UDPService, fn refFunc) {
+	if s.Weighted != nil {
+		for i := range s.Weighted.Services {
+			s.Weighted.Services[i].Name = walkRef(fn, refService, s.Weighted.Services[i].Name)
+		}
+	}
+}
+
+// walkRef skips empty references, which are unset optional fields.
+func walkRef(fn refFunc, kind refKind, ref string) string {
+	if ref == "" {
+		return ref
+	}
+	return fn(kind, ref)
+}
+
+func walkRefList(fn refFunc, kind refKind, refs []string) {
+	for i, ref := range refs {
+		refs[i] = walkRef(fn, kind, ref)
+	}
+}
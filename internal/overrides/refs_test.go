@@ -0,0 +1,99 @@
+package overrides
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+)
+
+func TestWalkRefs_VisitsEveryReference(t *testing.T) {
+	cfg := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{"api": {Service: "api", Middlewares: []string{"secured"}}},
+			Services: map[string]*dynamic.Service{
+				"api":     {LoadBalancer: &dynamic.ServersLoadBalancer{ServersTransport: "tunnel"}},
+				"canary":  {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api-v2"}}}},
+				"shadow":  {Mirroring: &dynamic.Mirroring{Service: "api", Mirrors: []dynamic.MirrorService{{Name: "api-v2"}}}},
+				"standby": {Failover: &dynamic.Failover{Service: "api", Fallback: "external@file"}},
+			},
+			Middlewares: map[string]*dynamic.Middleware{
+				"secured": {Chain: &dynamic.Chain{Middlewares: []string{"errors", "auth@file"}}},
+				"errors":  {Errors: &dynamic.ErrorPage{Service: "pages"}},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Routers:  map[string]*dynamic.TCPRouter{"db": {Service: "db", Middlewares: []string{"allow"}}},
+			Services: map[string]*dynamic.TCPService{"db-wrr": {Weighted: &dynamic.TCPWeightedRoundRobin{Services: []dynamic.TCPWRRService{{Name: "db"}}}}},
+		},
+		UDP: &dynamic.UDPConfiguration{
+			Routers:  map[string]*dynamic.UDPRouter{"dns": {Service: "dns"}},
+			Services: map[string]*dynamic.UDPService{"dns-wrr": {Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns"}}}}},
+		},
+	}
+	var got []string
+	collect := func(kind refKind, ref string) string {
+		got = append(got, ref)
+		return ref
+	}
+	walkHTTPRefs(cfg.HTTP, collect)
+	walkTCPRefs(cfg.TCP, collect)
+	walkUDPRefs(cfg.UDP, collect)
+	sort.Strings(got)
+
+	want := []string{
+		"allow", "api", "api", "api", "api-v2", "api-v2", "auth@file",
+		"db", "db", "dns", "dns", "errors", "external@file", "pages", "secured", "tunnel",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestStripProviders_NestedReferences(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{
+		Services: map[string]*dynamic.Service{
+			"canary@docker":  {Weighted: &dynamic.WeightedRoundRobin{Services: []dynamic.WRRService{{Name: "api@docker"}, {Name: "api-v2@docker"}}}},
+			"shadow@docker":  {Mirroring: &dynamic.Mirroring{Service: "api@docker", Mirrors: []dynamic.MirrorService{{Name: "api-v2@docker"}}}},
+			"standby@docker": {Failover: &dynamic.Failover{Service: "api@docker", Fallback: "noop@internal"}},
+		},
+		Middlewares: map[string]*dynamic.Middleware{
+			"secured@docker": {Chain: &dynamic.Chain{Middlewares: []string{"errors@docker", "auth@file"}}},
+			"errors@docker":  {Errors: &dynamic.ErrorPage{Service: "api@docker"}},
+		},
+	}
+	StripProvidersHTTP(httpConfig, "internal")
+
+	if got := httpConfig.Services["canary"].Weighted.Services; got[0].Name != "api" || got[1].Name != "api-v2" {
+		t.Errorf("weighted=%+v", got)
+	}
+	if m := httpConfig.Services["shadow"].Mirroring; m.Service != "api" || m.Mirrors[0].Name != "api-v2" {
+		t.Errorf("mirroring=%+v", m)
+	}
+	if f := httpConfig.Services["standby"].Failover; f.Service != "api" || f.Fallback != "noop@internal" {
+		t.Errorf("failover=%+v", f)
+	}
+	if got := httpConfig.Middlewares["secured"].Chain.Middlewares; !reflect.DeepEqual(got, []string{"errors", "auth"}) {
+		t.Errorf("chain=%v", got)
+	}
+	if got := httpConfig.Middlewares["errors"].Errors.Service; got != "api" {
+		t.Errorf("errors service=%q", got)
+	}
+
+	tcpConfig := &dynamic.TCPConfiguration{Services: map[string]*dynamic.TCPService{
+		"db-wrr@docker": {Weighted: &dynamic.TCPWeightedRoundRobin{Services: []dynamic.TCPWRRService{{Name: "db@docker"}}}},
+	}}
+	StripProvidersTCP(tcpConfig)
+	if got := tcpConfig.Services["db-wrr"].Weighted.Services[0].Name; got != "db" {
+		t.Errorf("tcp weighted=%q", got)
+	}
+
+	udpConfig := &dynamic.UDPConfiguration{Services: map[string]*dynamic.UDPService{
+		"dns-wrr@docker": {Weighted: &dynamic.UDPWeightedRoundRobin{Services: []dynamic.UDPWRRService{{Name: "dns@docker"}}}},
+	}}
+	StripProvidersUDP(udpConfig)
+	if got := udpConfig.Services["dns-wrr"].Weighted.Services[0].Name; got != "dns" {
+		t.Errorf("udp weighted=%q", got)
+	}
+}
@@ -0,0 +1,196 @@
+package overrides
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/jsonpatch"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// applyRemovals applies each removal to the resources returned by sel for its
+// matcher and returns the names of the resources that were dropped. A resource
+// that fails is left untouched and the failure is returned.
+func applyRemovals[T any](items map[string]*T, removals []config.Removal, kind string, sel func(matcher string) map[string]*T) ([]string, []error) {
+	var dropped []string
+	var errs []error
+	for i, r := range removals {
+		for name, item := range sel(r.Matcher) {
+			if r.Resource {
+				delete(items, name)
+				dropped = append(dropped, name)
+				continue
+			}
+			updated, err := removeFromResource(item, r)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s %q: remove %d: %w", kind, name, i, err))
+				continue
+			}
+			items[name] = updated
+		}
+	}
+	return dropped, errs
+}
+
+// removeFromResource removes the fields and list items described by r from the
+// JSON form of v and decodes the result into a new value.
+func removeFromResource[T any](v *T, r config.Removal) (*T, error) {
+	doc, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range r.Fields {
+		if _, err := jsonpatch.Get(doc, field); err != nil {
+			continue
+		}
+		if doc, err = jsonpatch.Apply(doc, []jsonpatch.Operation{{Op: "remove", Path: field}}); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range r.Items {
+		current, err := jsonpatch.Get(doc, item.Path)
+		if err != nil {
+			continue
+		}
+		list, ok := current.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not a list", item.Path)
+		}
+		re, err := regexp.Compile(item.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", item.Pattern, err)
+		}
+		kept := make([]interface{}, 0, len(list))
+		for _, elem := range list {
+			if !itemMatches(elem, item.Field, re) {
+				kept = append(kept, elem)
+			}
+		}
+		if doc, err = jsonpatch.Apply(doc, []jsonpatch.Operation{{Op: "replace", Path: item.Path, Value: kept}}); err != nil {
+			return nil, err
+		}
+	}
+	return decodeResource[T](doc)
+}
+
+// itemMatches reports whether a list element, or its field member for
+// objects, is a string matching re.
+func itemMatches(elem interface{}, field string, re *regexp.Regexp) bool {
+	if obj, ok := elem.(map[string]interface{}); ok && field != "" {
+		elem = obj[field]
+	}
+	s, ok := elem.(string)
+	return ok && re.MatchString(s)
+}
+
+// removeReferences removes names from a middleware list.
+func removeReferences(list []string, names map[string]bool) []string {
+	if len(list) == 0 {
+		return list
+	}
+	out := make([]string, 0, len(list))
+	for _, name := range list {
+		if !names[name] {
+			out = append(out, name)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// RemoveHTTPRouters applies removals to matching HTTP routers.
+func RemoveHTTPRouters(routers map[string]*dynamic.Router, removals []config.Removal) []error {
+	_, errs := applyRemovals(routers, removals, "router", func(m string) map[string]*dynamic.Router {
+		return matchers.HTTPRouters(routers, &config.RoutersConfig{Matcher: m, DiscoverPriority: true}, "")
+	})
+	return errs
+}
+
+// RemoveHTTPServices applies removals to matching HTTP services.
+func RemoveHTTPServices(services map[string]*dynamic.Service, removals []config.Removal) []error {
+	_, errs := applyRemovals(services, removals, "service", func(m string) map[string]*dynamic.Service {
+		return matchers.HTTPServices(services, &config.ServicesConfig{Matcher: m}, "")
+	})
+	return errs
+}
+
+// RemoveHTTPMiddlewares applies removals to matching HTTP middlewares. Dropped
+// middlewares are also removed from router middleware lists and chains.
+func RemoveHTTPMiddlewares(httpConfig *dynamic.HTTPConfiguration, removals []config.Removal) []error {
+	dropped, errs := applyRemovals(httpConfig.Middlewares, removals, "middleware", func(m string) map[string]*dynamic.Middleware {
+		return matchers.HTTPMiddlewares(httpConfig.Middlewares, &config.MiddlewaresConfig{Matcher: m}, "")
+	})
+	if len(dropped) == 0 {
+		return errs
+	}
+	names := nameSet(dropped)
+	for _, r := range httpConfig.Routers {
+		r.Middlewares = removeReferences(r.Middlewares, names)
+	}
+	for _, mw := range httpConfig.Middlewares {
+		if mw.Chain != nil {
+			mw.Chain.Middlewares = removeReferences(mw.Chain.Middlewares, names)
+		}
+	}
+	return errs
+}
+
+// RemoveTCPRouters applies removals to matching TCP routers.
+func RemoveTCPRouters(routers map[string]*dynamic.TCPRouter, removals []config.Removal) []error {
+	_, errs := applyRemovals(routers, removals, "tcp router", func(m string) map[string]*dynamic.TCPRouter {
+		return matchers.TCPRouters(routers, &config.RoutersConfig{Matcher: m}, "")
+	})
+	return errs
+}
+
+// RemoveTCPServices applies removals to matching TCP services.
+func RemoveTCPServices(services map[string]*dynamic.TCPService, removals []config.Removal) []error {
+	_, errs := applyRemovals(services, removals, "tcp service", func(m string) map[string]*dynamic.TCPService {
+		return matchers.TCPServices(services, &config.ServicesConfig{Matcher: m}, "")
+	})
+	return errs
+}
+
+// RemoveTCPMiddlewares applies removals to matching TCP middlewares. Dropped
+// middlewares are also removed from TCP router middleware lists.
+func RemoveTCPMiddlewares(tcpConfig *dynamic.TCPConfiguration, removals []config.Removal) []error {
+	dropped, errs := applyRemovals(tcpConfig.Middlewares, removals, "tcp middleware", func(m string) map[string]*dynamic.TCPMiddleware {
+		return matchers.TCPMiddlewares(tcpConfig.Middlewares, &config.MiddlewaresConfig{Matcher: m}, "")
+	})
+	if len(dropped) == 0 {
+		return errs
+	}
+	names := nameSet(dropped)
+	for _, r := range tcpConfig.Routers {
+		r.Middlewares = removeReferences(r.Middlewares, names)
+	}
+	return errs
+}
+
+// RemoveUDPRouters applies removals to matching UDP routers.
+func RemoveUDPRouters(routers map[string]*dynamic.UDPRouter, removals []config.Removal) []error {
+	_, errs := applyRemovals(routers, removals, "udp router", func(m string) map[string]*dynamic.UDPRouter {
+		return matchers.UDPRouters(routers, &config.UDPRoutersConfig{Matcher: m}, "")
+	})
+	return errs
+}
+
+// RemoveUDPServices applies removals to matching UDP services.
+func RemoveUDPServices(services map[string]*dynamic.UDPService, removals []config.Removal) []error {
+	_, errs := applyRemovals(services, removals, "udp service", func(m string) map[string]*dynamic.UDPService {
+		return matchers.UDPServices(services, &config.UDPServicesConfig{Matcher: m}, "")
+	})
+	return errs
+}
@@ -0,0 +1,136 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/traefik/genconf/dynamic/types"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestRemoveHTTPRouters_FieldsAndItems(t *testing.T) {
+	routers := map[string]*dynamic.Router{
+		"api": {
+			EntryPoints: []string{"web", "internal"},
+			Middlewares: []string{"ipwhitelist-lb", "auth"},
+			TLS:         &dynamic.RouterTLSConfig{CertResolver: "le", Domains: []types.Domain{{Main: "api.upstream"}}},
+		},
+		"other": {EntryPoints: []string{"internal"}},
+	}
+	removals := []config.Removal{{
+		Matcher: "Name(`api`)",
+		Fields:  []string{"/tls/domains", "/tls/options"},
+		Items: []config.RemoveItem{
+			{Path: "/middlewares", Pattern: "^ipwhitelist"},
+			{Path: "/entryPoints", Pattern: "^internal$"},
+			{Path: "/missing", Pattern: "x"},
+		},
+	}}
+
+	if errs := RemoveHTTPRouters(routers, removals); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	api := routers["api"]
+	if !reflect.DeepEqual(api.Middlewares, []string{"auth"}) || !reflect.DeepEqual(api.EntryPoints, []string{"web"}) {
+		t.Errorf("unexpected lists: middlewares=%v entrypoints=%v", api.Middlewares, api.EntryPoints)
+	}
+	if api.TLS == nil || api.TLS.Domains != nil || api.TLS.CertResolver != "le" {
+		t.Errorf("unexpected tls: %+v", api.TLS)
+	}
+	if !reflect.DeepEqual(routers["other"].EntryPoints, []string{"internal"}) {
+		t.Errorf("unmatched router changed: %+v", routers["other"])
+	}
+}
+
+func TestRemoveHTTPServices_ServersByField(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"svc": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://10.0.0.1"}, {URL: "http://public"}}}},
+	}
+	removals := []config.Removal{{Items: []config.RemoveItem{{Path: "/loadBalancer/servers", Field: "url", Pattern: `^http://10\.`}}}}
+
+	if errs := RemoveHTTPServices(services, removals); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := services["svc"].LoadBalancer.Servers; len(got) != 1 || got[0].URL != "http://public" {
+		t.Errorf("unexpected servers: %+v", got)
+	}
+}
+
+func TestRemoveHTTPMiddlewares_DropsReferences(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"api": {Middlewares: []string{"ipwhitelist", "auth"}},
+			"web": {Middlewares: []string{"ipwhitelist"}},
+		},
+		Middlewares: map[string]*dynamic.Middleware{
+			"ipwhitelist": {IPWhiteList: &dynamic.IPWhiteList{SourceRange: []string{"10.0.0.0/8"}}},
+			"auth":        {BasicAuth: &dynamic.BasicAuth{}},
+			"secure":      {Chain: &dynamic.Chain{Middlewares: []string{"ipwhitelist", "auth"}}},
+		},
+	}
+	removals := []config.Removal{{Matcher: "Name(`ipwhitelist`)", Resource: true}}
+
+	if errs := RemoveHTTPMiddlewares(httpConfig, removals); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if _, ok := httpConfig.Middlewares["ipwhitelist"]; ok {
+		t.Error("expected middleware to be dropped")
+	}
+	if got := httpConfig.Routers["api"].Middlewares; !reflect.DeepEqual(got, []string{"auth"}) {
+		t.Errorf("api middlewares=%v", got)
+	}
+	if got := httpConfig.Routers["web"].Middlewares; got != nil {
+		t.Errorf("web middlewares=%v want nil", got)
+	}
+	if got := httpConfig.Middlewares["secure"].Chain.Middlewares; !reflect.DeepEqual(got, []string{"auth"}) {
+		t.Errorf("chain middlewares=%v", got)
+	}
+}
+
+func TestRemove_ErrorLeavesResourceUntouched(t *testing.T) {
+	routers := map[string]*dynamic.TCPRouter{"db": {Rule: "HostSNI(`*`)", Service: "db"}}
+	removals := []config.Removal{{Items: []config.RemoveItem{{Path: "/rule", Pattern: "x"}}}}
+
+	if errs := RemoveTCPRouters(routers, removals); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if routers["db"].Rule != "HostSNI(`*`)" {
+		t.Errorf("router modified despite error: %+v", routers["db"])
+	}
+}
+
+func TestRemoveTCPAndUDP(t *testing.T) {
+	tcpConfig := &dynamic.TCPConfiguration{
+		Routers:     map[string]*dynamic.TCPRouter{"db": {Middlewares: []string{"allow", "other"}}},
+		Middlewares: map[string]*dynamic.TCPMiddleware{"allow": {}, "other": {}},
+		Services:    map[string]*dynamic.TCPService{"db": {LoadBalancer: &dynamic.TCPServersLoadBalancer{ProxyProtocol: &dynamic.ProxyProtocol{Version: 2}}}},
+	}
+	if errs := RemoveTCPMiddlewares(tcpConfig, []config.Removal{{Matcher: "Name(`allow`)", Resource: true}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := tcpConfig.Routers["db"].Middlewares; !reflect.DeepEqual(got, []string{"other"}) {
+		t.Errorf("tcp router middlewares=%v", got)
+	}
+	if errs := RemoveTCPServices(tcpConfig.Services, []config.Removal{{Fields: []string{"/loadBalancer/proxyProtocol"}}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if tcpConfig.Services["db"].LoadBalancer.ProxyProtocol != nil {
+		t.Error("expected proxyProtocol to be removed")
+	}
+
+	udpRouters := map[string]*dynamic.UDPRouter{"dns": {}, "ntp": {}}
+	if errs := RemoveUDPRouters(udpRouters, []config.Removal{{Matcher: "Name(`ntp`)", Resource: true}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if _, ok := udpRouters["ntp"]; ok || udpRouters["dns"] == nil {
+		t.Errorf("unexpected udp routers: %v", udpRouters)
+	}
+	udpServices := map[string]*dynamic.UDPService{"dns": {LoadBalancer: &dynamic.UDPServersLoadBalancer{Servers: []dynamic.UDPServer{{Address: "a:53"}, {Address: "b:53"}}}}}
+	if errs := RemoveUDPServices(udpServices, []config.Removal{{Items: []config.RemoveItem{{Path: "/loadBalancer/servers", Field: "address", Pattern: "^a:"}}}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if got := udpServices["dns"].LoadBalancer.Servers; len(got) != 1 || got[0].Address != "b:53" {
+		t.Errorf("udp servers=%+v", got)
+	}
+}
@@ -0,0 +1,98 @@
+package overrides
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+// serverEdit is a validated server override ready to apply to address lists.
+type serverEdit struct {
+	strategy string
+	values   []string
+	pattern  *regexp.Regexp
+}
+
+// newServerEdit resolves the strategy of o and validates its value and pattern.
+func newServerEdit(o config.OverrideServer) (*serverEdit, error) {
+	e := &serverEdit{strategy: o.Strategy}
+	switch v := o.Value.(type) {
+	case nil:
+	case string:
+		e.values = []string{v}
+		if e.strategy == "" {
+			e.strategy = config.ServerStrategyAppend
+		}
+	case []string:
+		e.values = v
+	default:
+		return nil, fmt.Errorf("server override %q: unsupported value type %T", o.Matcher, o.Value)
+	}
+	if e.strategy == "" {
+		if o.Value == nil {
+			return nil, fmt.Errorf("server override %q: a value is required", o.Matcher)
+		}
+		e.strategy = config.ServerStrategyReplace
+	}
+
+	switch e.strategy {
+	case config.ServerStrategyReplace, config.ServerStrategyAppend, config.ServerStrategyPrepend:
+		if len(e.values) == 0 {
+			return nil, fmt.Errorf("server override %q: strategy %q requires a value", o.Matcher, e.strategy)
+		}
+	case config.ServerStrategyDedupe:
+	case config.ServerStrategyRemove, config.ServerStrategyRewrite:
+		if o.Pattern == "" {
+			return nil, fmt.Errorf("server override %q: strategy %q requires a pattern", o.Matcher, e.strategy)
+		}
+		re, err := regexp.Compile(o.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("server override %q: invalid pattern: %w", o.Matcher, err)
+		}
+		e.pattern = re
+		if e.strategy == config.ServerStrategyRewrite && len(e.values) != 1 {
+			return nil, fmt.Errorf("server override %q: strategy %q requires a single replacement value", o.Matcher, e.strategy)
+		}
+	default:
+		return nil, fmt.Errorf("server override %q: invalid strategy %q", o.Matcher, e.strategy)
+	}
+	return e, nil
+}
+
+// apply returns the server URLs or addresses that result from applying e to current.
+func (e *serverEdit) apply(current []string) []string {
+	switch e.strategy {
+	case config.ServerStrategyReplace:
+		return append([]string(nil), e.values...)
+	case config.ServerStrategyAppend:
+		return append(append([]string(nil), current...), e.values...)
+	case config.ServerStrategyPrepend:
+		return append(append([]string(nil), e.values...), current...)
+	case config.ServerStrategyRemove:
+		out := make([]string, 0, len(current))
+		for _, addr := range current {
+			if !e.pattern.MatchString(addr) {
+				out = append(out, addr)
+			}
+		}
+		return out
+	case config.ServerStrategyRewrite:
+		out := make([]string, 0, len(current))
+		for _, addr := range current {
+			out = append(out, e.pattern.ReplaceAllString(addr, e.values[0]))
+		}
+		return out
+	case config.ServerStrategyDedupe:
+		seen := make(map[string]bool, len(current))
+		out := make([]string, 0, len(current))
+		for _, addr := range current {
+			if !seen[addr] {
+				seen[addr] = true
+				out = append(out, addr)
+			}
+		}
+		return out
+	}
+	return current
+}
@@ -0,0 +1,99 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestServerEdit_Strategies(t *testing.T) {
+	current := []string{"http://a:80", "http://b:80", "http://a:80"}
+	cases := []struct {
+		name string
+		o    config.OverrideServer
+		want []string
+	}{
+		{"default list replaces", config.OverrideServer{Value: []string{"http://n:80"}}, []string{"http://n:80"}},
+		{"default string appends", config.OverrideServer{Value: "http://n:80"}, []string{"http://a:80", "http://b:80", "http://a:80", "http://n:80"}},
+		{"replace", config.OverrideServer{Strategy: "replace", Value: "http://n:80"}, []string{"http://n:80"}},
+		{"append", config.OverrideServer{Strategy: "append", Value: []string{"http://n:80"}}, []string{"http://a:80", "http://b:80", "http://a:80", "http://n:80"}},
+		{"prepend", config.OverrideServer{Strategy: "prepend", Value: []string{"http://n:80"}}, []string{"http://n:80", "http://a:80", "http://b:80", "http://a:80"}},
+		{"remove", config.OverrideServer{Strategy: "remove", Pattern: "//a:"}, []string{"http://b:80"}},
+		{"rewrite", config.OverrideServer{Strategy: "rewrite", Pattern: `^http://(\w+):80$`, Value: "https://$1.internal:443"}, []string{"https://a.internal:443", "https://b.internal:443", "https://a.internal:443"}},
+		{"dedupe", config.OverrideServer{Strategy: "dedupe"}, []string{"http://a:80", "http://b:80"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e, err := newServerEdit(c.o)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := e.apply(current); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("got %v want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestServerEdit_Invalid(t *testing.T) {
+	cases := []config.OverrideServer{
+		{Strategy: "wrr", Value: []string{"http://a"}},
+		{Strategy: "remove"},
+		{Strategy: "rewrite", Pattern: "(", Value: "x"},
+		{Strategy: "rewrite", Pattern: "a", Value: []string{"x", "y"}},
+		{Value: 42},
+		{},
+		{Matcher: "Name(`api`)"},
+		{Strategy: "replace"},
+		{Strategy: "append", Value: []string{}},
+		{Strategy: "prepend"},
+		{Strategy: "rewrite", Pattern: "a"},
+	}
+	for _, o := range cases {
+		if _, err := newServerEdit(o); err == nil {
+			t.Errorf("expected error for %+v", o)
+		}
+	}
+}
+
+func TestOverrideServices_StrategiesAcrossProtocols(t *testing.T) {
+	httpServices := map[string]*dynamic.Service{
+		"web":      {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://old:80"}, {URL: "http://keep:80"}}}},
+		"weighted": {Weighted: &dynamic.WeightedRoundRobin{}},
+	}
+	errs := OverrideHTTPServices(httpServices, config.ServiceOverrides{Servers: []config.OverrideServer{
+		{Strategy: "remove", Pattern: "old", Matcher: "NameRegexp(`.*`)"},
+		{Strategy: "bogus", Matcher: "Name(`web`)"},
+	}}, nil)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for invalid strategy, got %v", errs)
+	}
+	if got := httpServices["web"].LoadBalancer.Servers; len(got) != 1 || got[0].URL != "http://keep:80" {
+		t.Errorf("http servers=%v", got)
+	}
+	if httpServices["weighted"].LoadBalancer != nil {
+		t.Errorf("weighted service should not get a load balancer")
+	}
+
+	tcpServices := map[string]*dynamic.TCPService{
+		"db": {LoadBalancer: &dynamic.TCPServersLoadBalancer{Servers: []dynamic.TCPServer{{Address: "db-1:5432"}}}},
+	}
+	OverrideTCPServices(tcpServices, config.ServiceOverrides{Servers: []config.OverrideServer{
+		{Strategy: "rewrite", Pattern: ":5432$", Value: ":6432", Matcher: "Name(`db`)"},
+	}}, nil)
+	if got := tcpServices["db"].LoadBalancer.Servers[0].Address; got != "db-1:6432" {
+		t.Errorf("tcp address=%q want db-1:6432", got)
+	}
+
+	udpServices := map[string]*dynamic.UDPService{
+		"dns": {LoadBalancer: &dynamic.UDPServersLoadBalancer{Servers: []dynamic.UDPServer{{Address: "a:53"}}}},
+	}
+	OverrideUDPServices(udpServices, config.ServiceOverrides{Servers: []config.OverrideServer{
+		{Strategy: "prepend", Value: "b:53", Matcher: "Name(`dns`)"},
+	}})
+	if got := udpServices["dns"].LoadBalancer.Servers; len(got) != 2 || got[0].Address != "b:53" {
+		t.Errorf("udp servers=%v", got)
+	}
+}
@@ -2,79 +2,89 @@
 package overrides
 
 import (
-	"strings"
-
 	"github.com/traefik/genconf/dynamic"
 	"github.com/zalbiraw/traefikprovider/config"
 )
 
-func applyOverrideTCP[T any](matched map[string]*dynamic.TCPRouter, value T, apply func(r *dynamic.TCPRouter, v T)) {
-	for key, router := range matched {
-		apply(router, value)
-		matched[key] = router
+// OverrideTCPRouters applies override rules to the given TCP routers map.
+// Override values are rendered as templates for each matched router.
+func OverrideTCPRouters(matched map[string]*dynamic.TCPRouter, overrides config.RouterOverrides, scope Scope) []error {
+	var errs []error
+	data := func(name string, r *dynamic.TCPRouter) TemplateData {
+		return routerData(scope, name, r.Rule, r.Service, r.EntryPoints)
 	}
-}
 
-func handleOverrideTCP(
-	matched map[string]*dynamic.TCPRouter,
-	value interface{},
-	applyArray func(r *dynamic.TCPRouter, arr []string),
-	applyString func(r *dynamic.TCPRouter, s string),
-) {
-	switch v := value.(type) {
-	case []string:
-		applyOverrideTCP(matched, v, applyArray)
-	case string:
-		applyOverrideTCP(matched, v, applyString)
-	}
-}
-
-// OverrideTCPRouters applies override rules to the given TCP routers map.
-func OverrideTCPRouters(matched map[string]*dynamic.TCPRouter, overrides config.RouterOverrides) {
 	for _, oep := range overrides.Entrypoints {
-		handleOverrideTCP(matched, oep.Value,
+		errs = append(errs, prefixErrors("tcp router entrypoint override", templateOverride(matched, oep.Matcher, oep.Value, data,
 			func(r *dynamic.TCPRouter, arr []string) { r.EntryPoints = arr },
 			func(r *dynamic.TCPRouter, s string) { r.EntryPoints = append(r.EntryPoints, s) },
-		)
+		))...)
 	}
 
 	for _, osvc := range overrides.Services {
-		applyOverrideTCP(matched, osvc.Value, func(r *dynamic.TCPRouter, v string) {
-			if strings.Contains(v, "$1") {
-				r.Service = strings.ReplaceAll(v, "$1", r.Service)
-			} else {
-				r.Service = v
-			}
-		})
+		errs = append(errs, prefixErrors("tcp router service override", templateOverride(matched, osvc.Matcher, osvc.Value, data,
+			nil,
+			func(r *dynamic.TCPRouter, v string) { r.Service = replaceOld(v, r.Service) },
+		))...)
 	}
 
 	for _, omw := range overrides.Middlewares {
-		handleOverrideTCP(matched, omw.Value,
+		errs = append(errs, prefixErrors("tcp router middleware override", templateOverride(matched, omw.Matcher, omw.Value, data,
 			func(r *dynamic.TCPRouter, arr []string) { r.Middlewares = arr },
 			func(r *dynamic.TCPRouter, s string) { r.Middlewares = append(r.Middlewares, s) },
-		)
+		))...)
 	}
+	return errs
 }
 
 // OverrideTCPServices applies overrides to matched TCP services.
-// If a server override specifies a Tunnel, the matched services' servers are
-// replaced with the tunnel addresses.
-func OverrideTCPServices(matched map[string]*dynamic.TCPService, overrides config.ServiceOverrides, tunnels []config.TunnelConfig) {
+// Server overrides only affect services with a load balancer. Invalid server
+// overrides are skipped and returned as errors.
+func OverrideTCPServices(matched map[string]*dynamic.TCPService, overrides config.ServiceOverrides, tunnels []config.TunnelConfig) []error {
+	var errs []error
 	// Server overrides
 	for _, orule := range overrides.Servers {
-		handleTCPServiceOverride(matched, orule.Matcher, orule.Value,
-			func(s *dynamic.TCPService, v []string) {
-				servers := []dynamic.TCPServer{}
-				for _, addr := range v {
-					server := dynamic.TCPServer{Address: addr}
-					servers = append(servers, server)
-				}
+		edit, err := newServerEdit(orule)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		applyTCPServiceOverride(matched, orule.Matcher, edit, func(s *dynamic.TCPService, e *serverEdit) {
+			if s.LoadBalancer == nil {
+				return
+			}
+			addresses := make([]string, 0, len(s.LoadBalancer.Servers))
+			for _, srv := range s.LoadBalancer.Servers {
+				addresses = append(addresses, srv.Address)
+			}
+			servers := []dynamic.TCPServer{}
+			for _, addr := range e.apply(addresses) {
+				servers = append(servers, dynamic.TCPServer{Address: addr})
+			}
+			s.LoadBalancer.Servers = servers
+		})
+	}
+	// Load balancer overrides
+	for _, olb := range overrides.LoadBalancers {
+		applyTCPServiceOverride(matched, olb.Matcher, olb, applyTCPLoadBalancer)
+	}
+	return errs
+}
 
-				s.LoadBalancer.Servers = servers
-			},
-			func(s *dynamic.TCPService, v string) {
-				s.LoadBalancer.Servers = append(s.LoadBalancer.Servers, dynamic.TCPServer{Address: v})
-			},
-		)
+// applyTCPLoadBalancer applies the TCP load balancer settings that are set in lb.
+func applyTCPLoadBalancer(s *dynamic.TCPService, lb config.OverrideLoadBalancer) {
+	if s.LoadBalancer == nil {
+		return
+	}
+	if lb.TerminationDelay != nil {
+		delay := *lb.TerminationDelay
+		s.LoadBalancer.TerminationDelay = &delay
+	}
+	if lb.ProxyProtocol != nil {
+		if lb.ProxyProtocol.Version == 0 {
+			s.LoadBalancer.ProxyProtocol = nil
+		} else {
+			s.LoadBalancer.ProxyProtocol = &dynamic.ProxyProtocol{Version: lb.ProxyProtocol.Version}
+		}
 	}
 }
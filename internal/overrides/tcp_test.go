@@ -42,7 +42,7 @@ func TestOverrideTCPRouters_EntrypointOverrideWithArray(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	expected := []string{"tcp-secure", "tcp-alt"}
 	if len(routers["tcp-router"].EntryPoints) != 2 {
@@ -68,7 +68,7 @@ func TestOverrideTCPRouters_EntrypointOverrideWithString(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	if len(routers["tcp-router"].EntryPoints) != 2 || routers["tcp-router"].EntryPoints[1] != "tcp-secure" {
 		t.Errorf("Expected entrypoints to include 'tcp-secure', got %v", routers["tcp-router"].EntryPoints)
@@ -92,7 +92,7 @@ func TestOverrideTCPRouters_ServiceOverride(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	if routers["tcp-router"].Service != "new-tcp-service" {
 		t.Errorf("Expected service 'new-tcp-service', got %s", routers["tcp-router"].Service)
@@ -116,7 +116,7 @@ func TestOverrideTCPRouters_ServiceOverrideWithReplacement(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	expected := "prefix-original-service-suffix"
 	if routers["tcp-router"].Service != expected {
@@ -142,7 +142,7 @@ func TestOverrideTCPRouters_MiddlewareOverrideWithArray(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	expected := []string{"tcp-auth", "tcp-ratelimit"}
 	if len(routers["tcp-router"].Middlewares) != 2 {
@@ -168,7 +168,7 @@ func TestOverrideTCPRouters_MiddlewareOverrideWithString(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	expected := []string{"existing", "tcp-auth"}
 	if len(routers["tcp-router"].Middlewares) != 2 {
@@ -185,7 +185,7 @@ func TestOverrideTCPRouters_EmptyOverrides(t *testing.T) {
 	}
 
 	overrides := config.RouterOverrides{}
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	if routers["tcp-router"].Service != "tcp-service" {
 		t.Errorf("Expected service to remain 'tcp-service', got %s", routers["tcp-router"].Service)
@@ -204,7 +204,7 @@ func TestOverrideTCPRouters_EmptyRouterMap(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	if len(routers) != 0 {
 		t.Errorf("Expected empty router map, got %d routers", len(routers))
@@ -242,7 +242,7 @@ func TestOverrideTCPRouters_AllOverrideTypesWithFiltering(t *testing.T) {
 		},
 	}
 
-	OverrideTCPRouters(routers, overrides)
+	OverrideTCPRouters(routers, overrides, Scope{})
 
 	if len(routers["tcp-router"].EntryPoints) != 1 || routers["tcp-router"].EntryPoints[0] != "tcp-secure" {
 		t.Errorf("Expected entrypoints [tcp-secure], got %v", routers["tcp-router"].EntryPoints)
@@ -350,3 +350,30 @@ func TestOverrideTCPServices(t *testing.T) {
 		}
 	})
 }
+
+func TestOverrideTCPServices_LoadBalancer(t *testing.T) {
+	delay := 200
+	services := map[string]*dynamic.TCPService{
+		"db":    {LoadBalancer: &dynamic.TCPServersLoadBalancer{}},
+		"cache": {LoadBalancer: &dynamic.TCPServersLoadBalancer{ProxyProtocol: &dynamic.ProxyProtocol{Version: 1}}},
+	}
+	overrides := config.ServiceOverrides{
+		LoadBalancers: []config.OverrideLoadBalancer{
+			{Matcher: "Name(`db`)", TerminationDelay: &delay, ProxyProtocol: &config.OverrideProxyProtocol{Version: 2}},
+			{Matcher: "Name(`cache`)", ProxyProtocol: &config.OverrideProxyProtocol{Version: 0}},
+		},
+	}
+
+	OverrideTCPServices(services, overrides, nil)
+
+	db := services["db"].LoadBalancer
+	if db.TerminationDelay == nil || *db.TerminationDelay != 200 {
+		t.Errorf("expected terminationDelay 200, got %v", db.TerminationDelay)
+	}
+	if db.ProxyProtocol == nil || db.ProxyProtocol.Version != 2 {
+		t.Errorf("expected proxyProtocol v2, got %+v", db.ProxyProtocol)
+	}
+	if services["cache"].LoadBalancer.ProxyProtocol != nil {
+		t.Errorf("expected proxyProtocol to be removed, got %+v", services["cache"].LoadBalancer.ProxyProtocol)
+	}
+}
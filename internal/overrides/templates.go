@@ -0,0 +1,180 @@
+package overrides
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/zalbiraw/traefikprovider/internal/rules"
+)
+
+// Scope describes the provider a set of resources was discovered from.
+type Scope struct {
+	ProviderConfigName string
+	// Providers maps stripped resource names to the provider suffix they carried upstream.
+	Providers map[string]string
+}
+
+// NewScope records the provider suffix of every key in m before it is stripped.
+func NewScope[T any](providerConfigName string, m map[string]*T) Scope {
+	providers := make(map[string]string, len(m))
+	for name := range m {
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			providers[name[:i]] = name[i+1:]
+		}
+	}
+	return Scope{ProviderConfigName: providerConfigName, Providers: providers}
+}
+
+// TemplateData holds the variables available to templated override values.
+type TemplateData struct {
+	Name               string
+	Provider           string
+	ProviderConfigName string
+	Rule               string
+	Service            string
+	Entrypoints        []string
+	// Captures holds the submatches of the first regexp matcher of the override
+	// matcher that matched the resource; index 0 is the whole match.
+	Captures []string
+}
+
+var templateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+}
+
+// parseTemplate parses text with the override template functions.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("value").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// valueTemplate is an override value compiled once and rendered per resource.
+type valueTemplate struct {
+	prog  *rules.Program
+	items []*template.Template
+	list  bool
+}
+
+// compileValue compiles a string or []string override value. It returns nil
+// for a nil value and an error for values of any other type.
+func compileValue(matcher string, value interface{}) (*valueTemplate, error) {
+	var items []string
+	vt := &valueTemplate{}
+	switch v := value.(type) {
+	case string:
+		items = []string{v}
+	case []string:
+		items, vt.list = v, true
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+	prog, err := rules.Compile(matcher)
+	if err != nil {
+		return nil, fmt.Errorf("matcher %q: %w", matcher, err)
+	}
+	vt.prog = prog
+	for _, item := range items {
+		tmpl, err := parseTemplate(item)
+		if err != nil {
+			return nil, fmt.Errorf("value %q: %w", item, err)
+		}
+		vt.items = append(vt.items, tmpl)
+	}
+	return vt, nil
+}
+
+// render executes the value for data, filling in captures from the matcher.
+// The result has the same shape (string or []string) as the configured value.
+func (vt *valueTemplate) render(data TemplateData) (interface{}, error) {
+	data.Captures = vt.prog.Captures(rules.Context{
+		Name:        data.Name,
+		Provider:    data.Provider,
+		Entrypoints: data.Entrypoints,
+		Service:     data.Service,
+	})
+	out := make([]string, 0, len(vt.items))
+	for _, tmpl := range vt.items {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		out = append(out, buf.String())
+	}
+	if vt.list {
+		return out, nil
+	}
+	return out[0], nil
+}
+
+// templateOverride renders value for every resource in selected and passes the
+// result to applyArray or applyString depending on its shape. Either apply
+// function may be nil when the override does not accept that shape.
+func templateOverride[R any](
+	selected map[string]*R,
+	matcher string,
+	value interface{},
+	data func(name string, r *R) TemplateData,
+	applyArray func(r *R, arr []string),
+	applyString func(r *R, s string),
+) []error {
+	vt, err := compileValue(matcher, value)
+	if err != nil {
+		return []error{err}
+	}
+	if vt == nil {
+		return nil
+	}
+	var errs []error
+	for name, r := range selected {
+		rendered, err := vt.render(data(name, r))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", name, err))
+			continue
+		}
+		switch v := rendered.(type) {
+		case []string:
+			if applyArray != nil {
+				applyArray(r, v)
+			}
+		case string:
+			if applyString != nil {
+				applyString(r, v)
+			}
+		}
+	}
+	return errs
+}
+
+// replaceOld substitutes "$1" in v with the current value.
+func replaceOld(v, old string) string {
+	if strings.Contains(v, "$1") {
+		return strings.ReplaceAll(v, "$1", old)
+	}
+	return v
+}
+
+// prefixErrors prepends prefix to every error in errs.
+func prefixErrors(prefix string, errs []error) []error {
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s: %w", prefix, err)
+	}
+	return errs
+}
+
+// routerData builds the template variables for a router.
+func routerData(scope Scope, name, rule, service string, entrypoints []string) TemplateData {
+	return TemplateData{
+		Name:               name,
+		Provider:           scope.Providers[name],
+		ProviderConfigName: scope.ProviderConfigName,
+		Rule:               rule,
+		Service:            service,
+		Entrypoints:        entrypoints,
+	}
+}
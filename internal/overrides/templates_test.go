@@ -0,0 +1,99 @@
+package overrides
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestNewScope_RecordsProviders(t *testing.T) {
+	scope := NewScope("prov1", map[string]*dynamic.Router{"api@docker": {}, "web": {}})
+	if scope.ProviderConfigName != "prov1" {
+		t.Errorf("ProviderConfigName=%q want prov1", scope.ProviderConfigName)
+	}
+	if !reflect.DeepEqual(scope.Providers, map[string]string{"api": "docker"}) {
+		t.Errorf("Providers=%v", scope.Providers)
+	}
+}
+
+func TestOverrideHTTPRouters_TemplateValues(t *testing.T) {
+	routers := map[string]*dynamic.Router{
+		"api-users": {Rule: "Host(`users`)", Service: "users", EntryPoints: []string{"web"}},
+		"other":     {Rule: "Host(`other`)", Service: "other"},
+	}
+	scope := Scope{ProviderConfigName: "prov1", Providers: map[string]string{"api-users": "docker"}}
+	overrides := config.RouterOverrides{
+		Rules: []config.OverrideRule{{
+			Matcher: "NameRegexp(`^api-(.*)$`)",
+			Value:   "Host(`{{.Name}}.{{.ProviderConfigName}}.internal`) || ($1)",
+		}},
+		Services: []config.OverrideService{{
+			Matcher: "NameRegexp(`^api-(.*)$`)",
+			Value:   "svc-{{index .Captures 1}}-{{.Provider}}",
+		}},
+		Middlewares: []config.OverrideMiddleware{{
+			Matcher: "Name(`api-users`)",
+			Value:   []string{"auth", "{{join .Entrypoints \"-\"}}-headers"},
+		}},
+	}
+
+	if errs := OverrideHTTPRouters(routers, overrides, scope); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	r := routers["api-users"]
+	if want := "Host(`api-users.prov1.internal`) || (Host(`users`))"; r.Rule != want {
+		t.Errorf("rule=%q want %q", r.Rule, want)
+	}
+	if r.Service != "svc-users-docker" {
+		t.Errorf("service=%q want svc-users-docker", r.Service)
+	}
+	if !reflect.DeepEqual(r.Middlewares, []string{"auth", "web-headers"}) {
+		t.Errorf("middlewares=%v", r.Middlewares)
+	}
+	if routers["other"].Service != "other" {
+		t.Errorf("unmatched router changed: %+v", routers["other"])
+	}
+}
+
+func TestOverrideHTTPRouters_TemplateErrors(t *testing.T) {
+	routers := map[string]*dynamic.Router{"api": {Service: "api"}}
+	overrides := config.RouterOverrides{
+		Services: []config.OverrideService{
+			{Matcher: "Name(`api`)", Value: "svc-{{index .Captures 1}}"},
+			{Matcher: "Name(`api`)", Value: "svc-{{.Name"},
+		},
+		Entrypoints: []config.OverrideEntrypoint{
+			{Matcher: "Name(`api`)", Value: []interface{}{"web"}},
+		},
+	}
+
+	errs := OverrideHTTPRouters(routers, overrides, Scope{})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	if routers["api"].EntryPoints != nil {
+		t.Errorf("entrypoints changed despite unsupported value: %v", routers["api"].EntryPoints)
+	}
+	if routers["api"].Service != "api" {
+		t.Errorf("service changed despite errors: %q", routers["api"].Service)
+	}
+}
+
+func TestOverrideTCPRouters_AppliesToEveryRouter(t *testing.T) {
+	routers := map[string]*dynamic.TCPRouter{
+		"db":    {Service: "db"},
+		"cache": {Service: "cache"},
+	}
+	overrides := config.RouterOverrides{
+		Services: []config.OverrideService{{Matcher: "Name(`db`)", Value: "{{.Name}}-{{.ProviderConfigName}}"}},
+	}
+
+	if errs := OverrideTCPRouters(routers, overrides, Scope{ProviderConfigName: "eu"}); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if routers["db"].Service != "db-eu" || routers["cache"].Service != "cache-eu" {
+		t.Fatalf("unexpected services: db=%q cache=%q", routers["db"].Service, routers["cache"].Service)
+	}
+}
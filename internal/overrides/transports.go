@@ -0,0 +1,106 @@
+package overrides
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/traefik/genconf/dynamic"
+	tlstypes "github.com/traefik/genconf/dynamic/tls"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// OverrideServersTransports applies overrides to matched HTTP servers transports.
+func OverrideServersTransports(matched map[string]*dynamic.ServersTransport, overrides []config.OverrideServersTransport) {
+	for _, o := range overrides {
+		rc := &config.ServersTransportsConfig{Matcher: o.Matcher}
+		for _, st := range matchers.HTTPServersTransports(matched, rc, "") {
+			applyServersTransport(st, o)
+		}
+	}
+}
+
+// applyServersTransport applies the servers transport settings that are set in o.
+func applyServersTransport(st *dynamic.ServersTransport, o config.OverrideServersTransport) {
+	if o.ServerName != "" {
+		st.ServerName = o.ServerName
+	}
+	if o.InsecureSkipVerify != nil {
+		st.InsecureSkipVerify = *o.InsecureSkipVerify
+	}
+	if o.RootCAs != nil {
+		st.RootCAs = append([]string(nil), o.RootCAs...)
+	}
+	if o.Certificates != nil {
+		st.Certificates = append(tlstypes.Certificates(nil), o.Certificates...)
+	}
+	if o.MaxIdleConnsPerHost != nil {
+		st.MaxIdleConnsPerHost = *o.MaxIdleConnsPerHost
+	}
+	if ft := o.ForwardingTimeouts; ft != nil {
+		if st.ForwardingTimeouts == nil {
+			st.ForwardingTimeouts = &dynamic.ForwardingTimeouts{}
+		}
+		target := st.ForwardingTimeouts
+		setIfNotEmpty(&target.DialTimeout, ft.DialTimeout)
+		setIfNotEmpty(&target.ResponseHeaderTimeout, ft.ResponseHeaderTimeout)
+		setIfNotEmpty(&target.IdleConnTimeout, ft.IdleConnTimeout)
+		setIfNotEmpty(&target.ReadIdleTimeout, ft.ReadIdleTimeout)
+		setIfNotEmpty(&target.PingTimeout, ft.PingTimeout)
+	}
+	if o.DisableHTTP2 != nil {
+		st.DisableHTTP2 = *o.DisableHTTP2
+	}
+	if o.PeerCertURI != "" {
+		st.PeerCertURI = o.PeerCertURI
+	}
+}
+
+func setIfNotEmpty(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+// CheckServersTransportFiles returns an error for every root CA, certificate
+// or key file of transports that does not exist on this host. Upstream
+// transports reference paths on the upstream host, which Traefik would fail
+// to load here. Inline PEM content is not checked. missing holds the files
+// reported by the previous call: they are not reported again while they stay
+// missing, and are forgotten once they exist or are no longer referenced.
+func CheckServersTransportFiles(transports map[string]*dynamic.ServersTransport, missing map[string]bool) []error {
+	var errs []error
+	current := map[string]bool{}
+	check := func(name, field, path string) {
+		if path == "" || strings.Contains(path, "-----BEGIN") {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			key := "serversTransport " + name + " " + path
+			current[key] = true
+			if !missing[key] {
+				errs = append(errs, fmt.Errorf("serversTransport %q: %s: %w", name, field, err))
+			}
+		}
+	}
+	for _, name := range sortedKeys(transports) {
+		st := transports[name]
+		for i, ca := range st.RootCAs {
+			check(name, fmt.Sprintf("rootCAs[%d]", i), ca)
+		}
+		for i, c := range st.Certificates {
+			check(name, fmt.Sprintf("certificates[%d].certFile", i), c.CertFile)
+			check(name, fmt.Sprintf("certificates[%d].keyFile", i), c.KeyFile)
+		}
+	}
+	for key := range missing {
+		if !current[key] {
+			delete(missing, key)
+		}
+	}
+	for key := range current {
+		missing[key] = true
+	}
+	return errs
+}
@@ -0,0 +1,88 @@
+package overrides
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	tlstypes "github.com/traefik/genconf/dynamic/tls"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestOverrideServersTransports(t *testing.T) {
+	transports := map[string]*dynamic.ServersTransport{
+		"mtls":  {ServerName: "upstream", RootCAs: []string{"/etc/upstream/ca.pem"}, ForwardingTimeouts: &dynamic.ForwardingTimeouts{DialTimeout: "5s"}},
+		"plain": {ServerName: "plain"},
+	}
+	insecure := true
+	OverrideServersTransports(transports, []config.OverrideServersTransport{{
+		Matcher:            "Name(`mtls`)",
+		RootCAs:            []string{"/certs/ca.pem"},
+		Certificates:       []tlstypes.Certificate{{CertFile: "/certs/client.pem", KeyFile: "/certs/client.key"}},
+		InsecureSkipVerify: &insecure,
+		ForwardingTimeouts: &dynamic.ForwardingTimeouts{IdleConnTimeout: "90s"},
+	}})
+
+	st := transports["mtls"]
+	if !reflect.DeepEqual(st.RootCAs, []string{"/certs/ca.pem"}) || len(st.Certificates) != 1 || !st.InsecureSkipVerify {
+		t.Errorf("unexpected transport: %+v", st)
+	}
+	if st.ServerName != "upstream" || st.ForwardingTimeouts.DialTimeout != "5s" || st.ForwardingTimeouts.IdleConnTimeout != "90s" {
+		t.Errorf("expected unset fields to be kept: %+v", st)
+	}
+	if p := transports["plain"]; p.InsecureSkipVerify || p.RootCAs != nil {
+		t.Errorf("unmatched transport changed: %+v", p)
+	}
+}
+
+func TestCheckServersTransportFiles(t *testing.T) {
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(ca, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	transports := map[string]*dynamic.ServersTransport{
+		"local":  {RootCAs: []string{ca, "-----BEGIN CERTIFICATE-----\n..."}},
+		"remote": {Certificates: tlstypes.Certificates{{CertFile: "/missing/client.pem", KeyFile: "/missing/client.key"}}},
+	}
+	reported := map[string]bool{}
+	errs := CheckServersTransportFiles(transports, reported)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `"remote": certificates[0].certFile`) {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+	if errs := CheckServersTransportFiles(transports, reported); len(errs) != 0 {
+		t.Errorf("expected missing files to be reported once, got %v", errs)
+	}
+
+	transports["other"] = &dynamic.ServersTransport{RootCAs: []string{"/missing/client.pem"}}
+	if errs := CheckServersTransportFiles(transports, reported); len(errs) != 1 {
+		t.Errorf("expected the new transport to be reported, got %v", errs)
+	}
+}
+
+func TestCheckServersTransportFiles_ReportsAgainAfterReappearing(t *testing.T) {
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	transports := map[string]*dynamic.ServersTransport{"local": {RootCAs: []string{ca}}}
+	missing := map[string]bool{}
+
+	if errs := CheckServersTransportFiles(transports, missing); len(errs) != 1 {
+		t.Fatalf("expected the missing file to be reported, got %v", errs)
+	}
+	if err := os.WriteFile(ca, []byte("ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckServersTransportFiles(transports, missing); len(errs) != 0 || len(missing) != 0 {
+		t.Fatalf("expected the file to be forgotten once it exists, errs=%v missing=%v", errs, missing)
+	}
+	if err := os.Remove(ca); err != nil {
+		t.Fatal(err)
+	}
+	if errs := CheckServersTransportFiles(transports, missing); len(errs) != 1 {
+		t.Errorf("expected the file to be reported again, got %v", errs)
+	}
+}
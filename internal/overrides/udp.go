@@ -2,73 +2,60 @@
 package overrides
 
 import (
-	"strings"
-
 	"github.com/traefik/genconf/dynamic"
 	"github.com/zalbiraw/traefikprovider/config"
 )
 
-// OverrideUDPRouters applies override rules to the given UDP routers map.
-func applyOverrideUDP[T any](matched map[string]*dynamic.UDPRouter, value T, apply func(r *dynamic.UDPRouter, v T)) {
-	for key, router := range matched {
-		apply(router, value)
-		matched[key] = router
-	}
-}
-
-func handleOverrideUDP(
-	matched map[string]*dynamic.UDPRouter,
-	value interface{},
-	applyArray func(r *dynamic.UDPRouter, arr []string),
-	applyString func(r *dynamic.UDPRouter, s string),
-) {
-	switch v := value.(type) {
-	case []string:
-		applyOverrideUDP(matched, v, applyArray)
-	case string:
-		applyOverrideUDP(matched, v, applyString)
+// OverrideUDPRouters applies overrides to the provided UDP routers map.
+// Override values are rendered as templates for each matched router.
+func OverrideUDPRouters(matched map[string]*dynamic.UDPRouter, overrides config.UDPOverrides, scope Scope) []error {
+	var errs []error
+	data := func(name string, r *dynamic.UDPRouter) TemplateData {
+		return routerData(scope, name, "", r.Service, r.EntryPoints)
 	}
-}
 
-// OverrideUDPRouters applies overrides to the provided UDP routers map.
-func OverrideUDPRouters(matched map[string]*dynamic.UDPRouter, overrides config.UDPOverrides) {
 	for _, oep := range overrides.Entrypoints {
-		handleOverrideUDP(matched, oep.Value,
+		errs = append(errs, prefixErrors("udp router entrypoint override", templateOverride(matched, oep.Matcher, oep.Value, data,
 			func(r *dynamic.UDPRouter, arr []string) { r.EntryPoints = arr },
 			func(r *dynamic.UDPRouter, s string) { r.EntryPoints = append(r.EntryPoints, s) },
-		)
+		))...)
 	}
 
 	for _, osvc := range overrides.Services {
-		applyOverrideUDP(matched, osvc.Value, func(r *dynamic.UDPRouter, v string) {
-			if strings.Contains(v, "$1") {
-				r.Service = strings.ReplaceAll(v, "$1", r.Service)
-			} else {
-				r.Service = v
-			}
-		})
+		errs = append(errs, prefixErrors("udp router service override", templateOverride(matched, osvc.Matcher, osvc.Value, data,
+			nil,
+			func(r *dynamic.UDPRouter, v string) { r.Service = replaceOld(v, r.Service) },
+		))...)
 	}
+	return errs
 }
 
 // OverrideUDPServices applies overrides to matched UDP services.
-// If a server override specifies a Tunnel, the matched services' servers are
-// replaced with the tunnel addresses.
-func OverrideUDPServices(matched map[string]*dynamic.UDPService, overrides config.ServiceOverrides) {
+// Server overrides only affect services with a load balancer. Invalid server
+// overrides are skipped and returned as errors.
+func OverrideUDPServices(matched map[string]*dynamic.UDPService, overrides config.ServiceOverrides) []error {
+	var errs []error
 	// Server overrides
 	for _, orule := range overrides.Servers {
-		handleUDPServiceOverride(matched, orule.Matcher, orule.Value,
-			func(s *dynamic.UDPService, v []string) {
-				servers := []dynamic.UDPServer{}
-				for _, addr := range v {
-					server := dynamic.UDPServer{Address: addr}
-					servers = append(servers, server)
-				}
-
-				s.LoadBalancer.Servers = servers
-			},
-			func(s *dynamic.UDPService, v string) {
-				s.LoadBalancer.Servers = append(s.LoadBalancer.Servers, dynamic.UDPServer{Address: v})
-			},
-		)
+		edit, err := newServerEdit(orule)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		applyUDPServiceOverride(matched, orule.Matcher, edit, func(s *dynamic.UDPService, e *serverEdit) {
+			if s.LoadBalancer == nil {
+				return
+			}
+			addresses := make([]string, 0, len(s.LoadBalancer.Servers))
+			for _, srv := range s.LoadBalancer.Servers {
+				addresses = append(addresses, srv.Address)
+			}
+			servers := []dynamic.UDPServer{}
+			for _, addr := range e.apply(addresses) {
+				servers = append(servers, dynamic.UDPServer{Address: addr})
+			}
+			s.LoadBalancer.Servers = servers
+		})
 	}
+	return errs
 }
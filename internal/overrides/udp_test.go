@@ -25,7 +25,7 @@ func TestOverrideUDPRouters(t *testing.T) {
 			},
 		}
 
-		OverrideUDPRouters(routers, overrides)
+		OverrideUDPRouters(routers, overrides, Scope{})
 
 		expected := []string{"udp-secure", "udp-alt"}
 		if len(routers["udp-router"].EntryPoints) != 2 {
@@ -50,7 +50,7 @@ func TestOverrideUDPRouters(t *testing.T) {
 			},
 		}
 
-		OverrideUDPRouters(routers, overrides)
+		OverrideUDPRouters(routers, overrides, Scope{})
 
 		if len(routers["udp-router"].EntryPoints) != 2 || routers["udp-router"].EntryPoints[1] != "udp-secure" {
 			t.Errorf("Expected entrypoints to include 'udp-secure', got %v", routers["udp-router"].EntryPoints)
@@ -73,7 +73,7 @@ func TestOverrideUDPRouters(t *testing.T) {
 			},
 		}
 
-		OverrideUDPRouters(routers, overrides)
+		OverrideUDPRouters(routers, overrides, Scope{})
 
 		if routers["udp-router"].Service != "new-udp-service" {
 			t.Errorf("Expected service 'new-udp-service', got %s", routers["udp-router"].Service)
@@ -96,7 +96,7 @@ func TestOverrideUDPRouters(t *testing.T) {
 			},
 		}
 
-		OverrideUDPRouters(routers, overrides)
+		OverrideUDPRouters(routers, overrides, Scope{})
 
 		expected := "prefix-original-service-suffix"
 		if routers["udp-router"].Service != expected {
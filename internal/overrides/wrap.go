@@ -0,0 +1,93 @@
+package overrides
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal/matchers"
+)
+
+// defaultWrapOrigin names the child service holding the original load balancer.
+const defaultWrapOrigin = "$1-origin"
+
+// WrapHTTPServices rewrites matching load balancer services into weighted,
+// failover or mirroring services. The original load balancer moves into a
+// generated child service, so routers referencing the service are unchanged.
+// Services without a load balancer are left alone. A wrap whose child name is
+// already taken is skipped and returned as an error.
+func WrapHTTPServices(services map[string]*dynamic.Service, wraps []config.OverrideWrap) []error {
+	var errs []error
+	for _, w := range wraps {
+		origin := w.Origin
+		if origin == "" {
+			origin = defaultWrapOrigin
+		}
+		// Names are collected first: without a matcher, selected is services
+		// itself, which gains the children created below.
+		matched := matchers.HTTPServices(services, &config.ServicesConfig{Matcher: w.Matcher}, "")
+		selected := make([]string, 0, len(matched))
+		for name := range matched {
+			selected = append(selected, name)
+		}
+		sort.Strings(selected)
+		created := map[string]bool{}
+		for _, name := range selected {
+			svc := services[name]
+			if created[name] || svc.LoadBalancer == nil {
+				continue
+			}
+			child := replaceOld(origin, name)
+			if _, exists := services[child]; exists || child == name {
+				errs = append(errs, fmt.Errorf("service %q: %s wrap: child service %q already exists", name, w.Type, child))
+				continue
+			}
+			wrapped, err := wrapService(w, child)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("service %q: %w", name, err))
+				continue
+			}
+			services[child] = &dynamic.Service{LoadBalancer: svc.LoadBalancer}
+			services[name] = wrapped
+			created[child] = true
+		}
+	}
+	return errs
+}
+
+// wrapService builds the wrapping service for w around the child service.
+func wrapService(w config.OverrideWrap, child string) (*dynamic.Service, error) {
+	switch w.Type {
+	case config.WrapWeighted:
+		wrr := &dynamic.WeightedRoundRobin{
+			Services: []dynamic.WRRService{{Name: child, Weight: copyInt(w.Weight)}},
+		}
+		for _, s := range w.Services {
+			wrr.Services = append(wrr.Services, dynamic.WRRService{Name: s.Name, Weight: copyInt(s.Weight)})
+		}
+		return &dynamic.Service{Weighted: wrr}, nil
+	case config.WrapFailover:
+		return &dynamic.Service{Failover: &dynamic.Failover{Service: child, Fallback: w.Fallback}}, nil
+	case config.WrapMirroring:
+		mirroring := &dynamic.Mirroring{Service: child}
+		if w.MaxBodySize != nil {
+			size := *w.MaxBodySize
+			mirroring.MaxBodySize = &size
+		}
+		for _, m := range w.Mirrors {
+			mirroring.Mirrors = append(mirroring.Mirrors, dynamic.MirrorService{Name: m.Name, Percent: m.Percent})
+		}
+		return &dynamic.Service{Mirroring: mirroring}, nil
+	default:
+		return nil, fmt.Errorf("unsupported wrap type %q", w.Type)
+	}
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,104 @@
+package overrides
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestWrapHTTPServices_Weighted(t *testing.T) {
+	lb := &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://a"}}}
+	services := map[string]*dynamic.Service{"api": {LoadBalancer: lb}}
+	originWeight, copyWeight := 3, 1
+	wraps := []config.OverrideWrap{{
+		Type:     config.WrapWeighted,
+		Matcher:  "Name(`api`)",
+		Weight:   &originWeight,
+		Services: []config.WeightedService{{Name: "api-copy", Weight: &copyWeight}},
+	}}
+
+	if errs := WrapHTTPServices(services, wraps); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if services["api-origin"] == nil || services["api-origin"].LoadBalancer != lb {
+		t.Fatalf("expected load balancer to move to api-origin, got %+v", services["api-origin"])
+	}
+	wrr := services["api"].Weighted
+	if services["api"].LoadBalancer != nil || wrr == nil || len(wrr.Services) != 2 {
+		t.Fatalf("unexpected wrapped service: %+v", services["api"])
+	}
+	if wrr.Services[0].Name != "api-origin" || *wrr.Services[0].Weight != 3 {
+		t.Errorf("unexpected origin entry: %+v", wrr.Services[0])
+	}
+	if wrr.Services[1].Name != "api-copy" || *wrr.Services[1].Weight != 1 {
+		t.Errorf("unexpected copy entry: %+v", wrr.Services[1])
+	}
+}
+
+func TestWrapHTTPServices_FailoverAndMirroring(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"web":  {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+		"shop": {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+		"wrr":  {Weighted: &dynamic.WeightedRoundRobin{}},
+	}
+	wraps := []config.OverrideWrap{
+		{Type: config.WrapFailover, Matcher: "Name(`web`)", Fallback: "maintenance"},
+		{Type: config.WrapMirroring, Matcher: "Name(`shop`) || Name(`wrr`)", Origin: "primary-$1", Mirrors: []config.MirrorService{{Name: "shadow", Percent: 5}}},
+	}
+
+	if errs := WrapHTTPServices(services, wraps); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if f := services["web"].Failover; f == nil || f.Service != "web-origin" || f.Fallback != "maintenance" {
+		t.Errorf("unexpected failover: %+v", services["web"])
+	}
+	m := services["shop"].Mirroring
+	if m == nil || m.Service != "primary-shop" || len(m.Mirrors) != 1 || m.Mirrors[0] != (dynamic.MirrorService{Name: "shadow", Percent: 5}) {
+		t.Errorf("unexpected mirroring: %+v", services["shop"])
+	}
+	if services["primary-shop"] == nil || services["primary-shop"].LoadBalancer == nil {
+		t.Error("expected primary-shop child service")
+	}
+	if _, ok := services["primary-wrr"]; ok || services["wrr"].Mirroring != nil {
+		t.Error("expected service without load balancer to be left alone")
+	}
+}
+
+func TestWrapHTTPServices_ChildExists(t *testing.T) {
+	services := map[string]*dynamic.Service{
+		"api":        {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+		"api-origin": {LoadBalancer: &dynamic.ServersLoadBalancer{}},
+	}
+	wraps := []config.OverrideWrap{{Type: config.WrapFailover, Matcher: "Name(`api`)", Fallback: "down"}}
+
+	if errs := WrapHTTPServices(services, wraps); len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if services["api"].LoadBalancer == nil || services["api"].Failover != nil {
+		t.Errorf("expected api to be left alone, got %+v", services["api"])
+	}
+}
+
+func TestWrapHTTPServices_EmptyMatcher(t *testing.T) {
+	for run := 0; run < 5; run++ {
+		services := map[string]*dynamic.Service{}
+		for i := 0; i < 20; i++ {
+			services[fmt.Sprintf("svc%d", i)] = &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{}}
+		}
+		wraps := []config.OverrideWrap{{Type: config.WrapFailover, Fallback: "maintenance"}}
+		if errs := WrapHTTPServices(services, wraps); len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if len(services) != 40 {
+			t.Fatalf("expected 40 services, got %d", len(services))
+		}
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("svc%d", i)
+			if services[name].Failover == nil || services[name+"-origin"].LoadBalancer == nil {
+				t.Fatalf("service %q not wrapped once: %+v", name, services[name])
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package parsers
 
 import (
 	"encoding/json"
+	"log"
 
 	"github.com/traefik/genconf/dynamic"
 	"github.com/zalbiraw/traefikprovider/config"
@@ -11,6 +12,105 @@ import (
 	"github.com/zalbiraw/traefikprovider/internal/tunnels"
 )
 
+// report logs errors produced while processing discovered resources.
+func report(errs []error) {
+	for _, err := range errs {
+		log.Printf("traefikprovider: %v", err)
+	}
+}
+
+// addExtras adds copies of the extras decoded at load time to *target,
+// creating the map when needed. Extras that were not decoded yet are decoded
+// here; invalid entries are skipped and logged.
+func addExtras[T any](target *map[string]*T, extras interface{}, path string) {
+	decoded, errs := config.DecodeExtras[T](extras)
+	for _, err := range errs {
+		log.Printf("traefikprovider: %s: %v", path, err)
+	}
+	if len(decoded) == 0 {
+		return
+	}
+	if *target == nil {
+		*target = make(map[string]*T, len(decoded))
+	}
+	for name, v := range decoded {
+		c, err := copyExtra(v)
+		if err != nil {
+			log.Printf("traefikprovider: %s: %q: %v", path, name, err)
+			continue
+		}
+		(*target)[name] = c
+	}
+}
+
+// copyExtra deep-copies a decoded extra so that overrides applied during a
+// poll do not leak into the next one.
+func copyExtra[T any](v *T) (*T, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// generate adds the resources rendered by gens to *target, creating the map
+// when the upstream had no resources of that kind.
+func generate[T any](target *map[string]*T, gens []config.Generator, sources map[string]interface{}, defaultSource string, providerCfg *config.ProviderConfig) {
+	if len(gens) == 0 {
+		return
+	}
+	if *target == nil {
+		*target = make(map[string]*T)
+	}
+	report(overrides.Generate(*target, gens, sources, defaultSource, providerCfg.Matcher, providerCfg.Name))
+}
+
+// includeDependencies adds the entries of upstream listed in keys to *target,
+// creating the map when needed.
+func includeDependencies[T any](target *map[string]*T, upstream map[string]*T, keys map[string]bool) {
+	if len(keys) == 0 {
+		return
+	}
+	if *target == nil {
+		*target = make(map[string]*T, len(keys))
+	}
+	for key := range keys {
+		if _, ok := (*target)[key]; !ok {
+			(*target)[key] = upstream[key]
+		}
+	}
+}
+
+// httpSources returns the raw HTTP resources generators can iterate over.
+func httpSources(raw map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		config.SourceRouters:     raw["routers"],
+		config.SourceServices:    raw["services"],
+		config.SourceMiddlewares: raw["middlewares"],
+	}
+}
+
+// tcpSources returns the raw TCP resources generators can iterate over.
+func tcpSources(raw map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		config.SourceRouters:     raw["tcpRouters"],
+		config.SourceServices:    raw["tcpServices"],
+		config.SourceMiddlewares: raw["tcpMiddlewares"],
+	}
+}
+
+// udpSources returns the raw UDP resources generators can iterate over.
+func udpSources(raw map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		config.SourceRouters:  raw["udpRouters"],
+		config.SourceServices: raw["udpServices"],
+	}
+}
+
 // convertToTyped converts a loosely-typed map to a map of typed pointers.
 //
 //nolint:nestif // deeply nested due to JSON shape handling
@@ -44,94 +144,126 @@ func ensureHTTPDefaults(pc *config.HTTPSection) {
 	if pc.Middlewares == nil {
 		pc.Middlewares = &config.MiddlewaresConfig{Discover: true}
 	}
+	if pc.Models == nil {
+		pc.Models = &config.ModelsConfig{Discover: true}
+	}
+	if pc.ServersTransports == nil {
+		pc.ServersTransports = &config.ServersTransportsConfig{Discover: true}
+	}
 }
 
-// ParseHTTPConfig fills httpConfig from raw data according to providerConfig and tunnels.
-func ParseHTTPConfig(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerConfig *config.HTTPSection, providerMatcher string, tns []config.TunnelConfig) {
-	ensureHTTPDefaults(providerConfig)
-	if providerConfig.Routers.Discover {
-		processHTTPRouters(raw, httpConfig, providerConfig, providerMatcher)
+// ParseHTTPConfig fills httpConfig from raw data according to the provider's HTTP section, matcher, and tunnels.
+func ParseHTTPConfig(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig) {
+	pc := providerCfg.HTTP
+	ensureHTTPDefaults(pc)
+	var scope overrides.Scope
+	if pc.Routers.Discover {
+		scope = processHTTPRouters(raw, httpConfig, providerCfg)
 	}
-	if providerConfig.Services.Discover {
-		processHTTPServices(raw, httpConfig, providerConfig, providerMatcher, tns)
+	// Servers transports are processed before services so that tunnels add theirs to the discovered ones.
+	if pc.ServersTransports.Discover {
+		processHTTPServersTransports(raw, httpConfig, providerCfg)
 	}
-	if providerConfig.Middlewares.Discover {
-		processHTTPMiddlewares(raw, httpConfig, providerConfig, providerMatcher)
+	if pc.Services.Discover {
+		processHTTPServices(raw, httpConfig, providerCfg, scope)
+	}
+	if pc.Middlewares.Discover {
+		processHTTPMiddlewares(raw, httpConfig, providerCfg, scope)
+	}
+	if pc.Models.Discover {
+		processHTTPModels(raw, httpConfig, providerCfg)
+	}
+	// Redirect routers are generated last because processing middlewares replaces the middlewares map.
+	if pc.Routers.Discover {
+		overrides.AddHTTPSRedirects(httpConfig, pc.Routers.HTTPSRedirect)
 	}
 }
 
-func processHTTPRouters(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, pc *config.HTTPSection, providerMatcher string) {
+// processHTTPRouters returns the scope recording the provider each router was discovered from.
+func processHTTPRouters(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig) overrides.Scope {
+	pc, providerMatcher := providerCfg.HTTP, providerCfg.Matcher
 	if routers, ok := raw["routers"]; ok {
 		typedRouters := convertToTyped[dynamic.Router](routers)
 		httpConfig.Routers = matchers.HTTPRouters(typedRouters, pc.Routers, providerMatcher)
 	}
-	for _, extra := range pc.Routers.ExtraRoutes {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var router dynamic.Router
-		if err := json.Unmarshal(b, &router); err != nil {
-			continue
-		}
-		if routerName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			httpConfig.Routers[routerName] = &router
-		}
-	}
-	overrides.StripProvidersHTTP(httpConfig)
-	overrides.OverrideHTTPRouters(httpConfig.Routers, pc.Routers.Overrides)
+	addExtras(&httpConfig.Routers, pc.Routers.ExtraRoutes, "http.routers.extraRoutes")
+	generate(&httpConfig.Routers, pc.Routers.Generators, httpSources(raw), config.SourceRouters, providerCfg)
+	scope := overrides.NewScope(providerCfg.Name, httpConfig.Routers)
+	overrides.StripProvidersHTTP(httpConfig, providerCfg.PreservedProviders()...)
+	overrides.MapHTTPEntrypoints(httpConfig.Routers, providerCfg.EntrypointMap)
+	report(overrides.OverrideHTTPRouters(httpConfig.Routers, pc.Routers.Overrides, scope))
 
 	if pc.Routers != nil && !pc.Routers.DiscoverPriority {
 		for _, r := range httpConfig.Routers {
 			r.Priority = 0
 		}
 	}
+	report(overrides.RemoveHTTPRouters(httpConfig.Routers, pc.Routers.Remove))
+	report(overrides.PatchHTTPRouters(httpConfig.Routers, pc.Routers.Patches))
+	if providerCfg.EnforceMiddlewares != nil {
+		overrides.EnforceHTTPMiddlewares(httpConfig.Routers, providerCfg.EnforceMiddlewares.HTTP)
+	}
+	return scope
 }
 
-func processHTTPServices(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, pc *config.HTTPSection, providerMatcher string, tns []config.TunnelConfig) {
+func processHTTPServices(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig, scope overrides.Scope) {
+	pc, providerMatcher, tns := providerCfg.HTTP, providerCfg.Matcher, providerCfg.Tunnels
 	if services, ok := raw["services"]; ok {
 		typedServices := convertToTyped[dynamic.Service](services)
 		httpConfig.Services = matchers.HTTPServices(typedServices, pc.Services, providerMatcher)
-	}
-	for _, extra := range pc.Services.ExtraServices {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var service dynamic.Service
-		if err := json.Unmarshal(b, &service); err != nil {
-			continue
-		}
-		if serviceName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			httpConfig.Services[serviceName] = &service
+		if pc.Routers.Discover && pc.Routers.IncludeDependencies {
+			deps, _ := overrides.HTTPDependencies(httpConfig.Routers, scope.Providers, typedServices, convertToTyped[dynamic.Middleware](raw["middlewares"]), providerCfg.PreservedProviders())
+			includeDependencies(&httpConfig.Services, typedServices, deps)
 		}
 	}
-	overrides.StripProvidersHTTP(httpConfig)
-	overrides.OverrideHTTPServices(httpConfig.Services, pc.Services.Overrides, tns)
+	addExtras(&httpConfig.Services, pc.Services.ExtraServices, "http.services.extraServices")
+	generate(&httpConfig.Services, pc.Services.Generators, httpSources(raw), config.SourceServices, providerCfg)
+	overrides.StripProvidersHTTP(httpConfig, providerCfg.PreservedProviders()...)
+	report(overrides.OverrideHTTPServices(httpConfig.Services, pc.Services.Overrides, tns))
 
 	// Apply tunnels by matcher after overrides
 	tunnels.ApplyHTTPTunnels(httpConfig, providerMatcher, tns)
+	report(overrides.WrapHTTPServices(httpConfig.Services, pc.Services.Overrides.Wraps))
+	report(overrides.RemoveHTTPServices(httpConfig.Services, pc.Services.Remove))
+	report(overrides.PatchHTTPServices(httpConfig.Services, pc.Services.Patches))
 }
 
-func processHTTPMiddlewares(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, pc *config.HTTPSection, providerMatcher string) {
+func processHTTPMiddlewares(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig, scope overrides.Scope) {
+	pc, providerMatcher := providerCfg.HTTP, providerCfg.Matcher
 	if middlewares, ok := raw["middlewares"]; ok {
 		typedMiddlewares := convertToTyped[dynamic.Middleware](middlewares)
 		httpConfig.Middlewares = matchers.HTTPMiddlewares(typedMiddlewares, pc.Middlewares, providerMatcher)
-	}
-	for _, extra := range pc.Middlewares.ExtraMiddlewares {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var middleware dynamic.Middleware
-		if err := json.Unmarshal(b, &middleware); err != nil {
-			continue
-		}
-		if middlewareName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			httpConfig.Middlewares[middlewareName] = &middleware
+		if pc.Routers.Discover && pc.Routers.IncludeDependencies {
+			_, deps := overrides.HTTPDependencies(httpConfig.Routers, scope.Providers, convertToTyped[dynamic.Service](raw["services"]), typedMiddlewares, providerCfg.PreservedProviders())
+			includeDependencies(&httpConfig.Middlewares, typedMiddlewares, deps)
 		}
 	}
-	overrides.StripProvidersHTTP(httpConfig)
+	addExtras(&httpConfig.Middlewares, pc.Middlewares.ExtraMiddlewares, "http.middlewares.extraMiddlewares")
+	generate(&httpConfig.Middlewares, pc.Middlewares.Generators, httpSources(raw), config.SourceMiddlewares, providerCfg)
+	overrides.StripProvidersHTTP(httpConfig, providerCfg.PreservedProviders()...)
+	report(overrides.RemoveHTTPMiddlewares(httpConfig, pc.Middlewares.Remove))
+	report(overrides.PatchHTTPMiddlewares(httpConfig.Middlewares, pc.Middlewares.Patches))
+}
+
+func processHTTPServersTransports(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig) {
+	pc, providerMatcher := providerCfg.HTTP, providerCfg.Matcher
+	if transports, ok := raw["serversTransports"]; ok {
+		typedTransports := convertToTyped[dynamic.ServersTransport](transports)
+		httpConfig.ServersTransports = matchers.HTTPServersTransports(typedTransports, pc.ServersTransports, providerMatcher)
+	}
+	addExtras(&httpConfig.ServersTransports, pc.ServersTransports.ExtraServersTransports, "http.serversTransports.extraServersTransports")
+	overrides.StripProvidersHTTP(httpConfig, providerCfg.PreservedProviders()...)
+	overrides.OverrideServersTransports(httpConfig.ServersTransports, pc.ServersTransports.Overrides)
+}
+
+func processHTTPModels(raw map[string]interface{}, httpConfig *dynamic.HTTPConfiguration, providerCfg *config.ProviderConfig) {
+	pc, providerMatcher := providerCfg.HTTP, providerCfg.Matcher
+	if models, ok := raw["models"]; ok {
+		typedModels := convertToTyped[dynamic.Model](models)
+		httpConfig.Models = matchers.HTTPModels(typedModels, pc.Models, providerMatcher)
+	}
+	addExtras(&httpConfig.Models, pc.Models.ExtraModels, "http.models.extraModels")
+	overrides.StripProvidersHTTP(httpConfig, providerCfg.PreservedProviders()...)
 }
 
 func ensureTCPDefaults(pc *config.TCPSection) {
@@ -146,92 +278,85 @@ func ensureTCPDefaults(pc *config.TCPSection) {
 	}
 }
 
-// ParseTCPConfig fills tcpConfig from raw data according to providerConfig and tunnels.
-func ParseTCPConfig(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, providerConfig *config.TCPSection, providerMatcher string, tns []config.TunnelConfig) {
-	ensureTCPDefaults(providerConfig)
-	if providerConfig.Routers.Discover {
-		processTCPRouters(raw, tcpConfig, providerConfig, providerMatcher)
+// ParseTCPConfig fills tcpConfig from raw data according to the provider's TCP section, matcher, and tunnels.
+func ParseTCPConfig(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, providerCfg *config.ProviderConfig) {
+	pc := providerCfg.TCP
+	ensureTCPDefaults(pc)
+	var scope overrides.Scope
+	if pc.Routers.Discover {
+		scope = processTCPRouters(raw, tcpConfig, providerCfg)
 	}
-	if providerConfig.Services.Discover {
-		processTCPServices(raw, tcpConfig, providerConfig, providerMatcher, tns)
+	if pc.Services.Discover {
+		processTCPServices(raw, tcpConfig, providerCfg, scope)
 	}
-	if providerConfig.Middlewares.Discover {
-		processTCPMiddlewares(raw, tcpConfig, providerConfig, providerMatcher)
+	if pc.Middlewares.Discover {
+		processTCPMiddlewares(raw, tcpConfig, providerCfg, scope)
 	}
 }
 
-func processTCPRouters(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, pc *config.TCPSection, providerMatcher string) {
+// processTCPRouters returns the scope recording the provider each router was discovered from.
+func processTCPRouters(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, providerCfg *config.ProviderConfig) overrides.Scope {
+	pc, providerMatcher := providerCfg.TCP, providerCfg.Matcher
 	if routers, ok := raw["tcpRouters"]; ok {
 		typedRouters := convertToTyped[dynamic.TCPRouter](routers)
 		tcpConfig.Routers = matchers.TCPRouters(typedRouters, pc.Routers, providerMatcher)
 	}
-	for _, extra := range pc.Routers.ExtraRoutes {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var router dynamic.TCPRouter
-		if err := json.Unmarshal(b, &router); err != nil {
-			continue
-		}
-		if routerName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			tcpConfig.Routers[routerName] = &router
-		}
-	}
-	overrides.StripProvidersTCP(tcpConfig)
-	overrides.OverrideTCPRouters(tcpConfig.Routers, pc.Routers.Overrides)
+	addExtras(&tcpConfig.Routers, pc.Routers.ExtraRoutes, "tcp.routers.extraRoutes")
+	generate(&tcpConfig.Routers, pc.Routers.Generators, tcpSources(raw), config.SourceRouters, providerCfg)
+	scope := overrides.NewScope(providerCfg.Name, tcpConfig.Routers)
+	overrides.StripProvidersTCP(tcpConfig, providerCfg.PreservedProviders()...)
+	overrides.MapTCPEntrypoints(tcpConfig.Routers, providerCfg.EntrypointMap)
+	report(overrides.OverrideTCPRouters(tcpConfig.Routers, pc.Routers.Overrides, scope))
 
 	if pc.Routers != nil && !pc.Routers.DiscoverPriority {
 		for _, r := range tcpConfig.Routers {
 			r.Priority = 0
 		}
 	}
+	report(overrides.RemoveTCPRouters(tcpConfig.Routers, pc.Routers.Remove))
+	report(overrides.PatchTCPRouters(tcpConfig.Routers, pc.Routers.Patches))
+	if providerCfg.EnforceMiddlewares != nil {
+		overrides.EnforceTCPMiddlewares(tcpConfig.Routers, providerCfg.EnforceMiddlewares.TCP)
+	}
+	return scope
 }
 
-func processTCPServices(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, pc *config.TCPSection, providerMatcher string, tns []config.TunnelConfig) {
+func processTCPServices(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, providerCfg *config.ProviderConfig, scope overrides.Scope) {
+	pc, providerMatcher, tns := providerCfg.TCP, providerCfg.Matcher, providerCfg.Tunnels
 	if services, ok := raw["tcpServices"]; ok {
 		typedServices := convertToTyped[dynamic.TCPService](services)
 		tcpConfig.Services = matchers.TCPServices(typedServices, pc.Services, providerMatcher)
-	}
-	for _, extra := range pc.Services.ExtraServices {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var service dynamic.TCPService
-		if err := json.Unmarshal(b, &service); err != nil {
-			continue
-		}
-		if serviceName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			tcpConfig.Services[serviceName] = &service
+		if pc.Routers.Discover && pc.Routers.IncludeDependencies {
+			deps, _ := overrides.TCPDependencies(tcpConfig.Routers, scope.Providers, typedServices, nil, providerCfg.PreservedProviders())
+			includeDependencies(&tcpConfig.Services, typedServices, deps)
 		}
 	}
-	overrides.StripProvidersTCP(tcpConfig)
-	overrides.OverrideTCPServices(tcpConfig.Services, pc.Services.Overrides, tns)
+	addExtras(&tcpConfig.Services, pc.Services.ExtraServices, "tcp.services.extraServices")
+	generate(&tcpConfig.Services, pc.Services.Generators, tcpSources(raw), config.SourceServices, providerCfg)
+	overrides.StripProvidersTCP(tcpConfig, providerCfg.PreservedProviders()...)
+	report(overrides.OverrideTCPServices(tcpConfig.Services, pc.Services.Overrides, tns))
 
 	// Apply tunnels by matcher after overrides
 	tunnels.ApplyTCPTunnels(tcpConfig, providerMatcher, tns)
+	report(overrides.RemoveTCPServices(tcpConfig.Services, pc.Services.Remove))
+	report(overrides.PatchTCPServices(tcpConfig.Services, pc.Services.Patches))
 }
 
-func processTCPMiddlewares(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, pc *config.TCPSection, providerMatcher string) {
+func processTCPMiddlewares(raw map[string]interface{}, tcpConfig *dynamic.TCPConfiguration, providerCfg *config.ProviderConfig, scope overrides.Scope) {
+	pc, providerMatcher := providerCfg.TCP, providerCfg.Matcher
 	if middlewares, ok := raw["tcpMiddlewares"]; ok {
 		typedMiddlewares := convertToTyped[dynamic.TCPMiddleware](middlewares)
 		tcpConfig.Middlewares = matchers.TCPMiddlewares(typedMiddlewares, pc.Middlewares, providerMatcher)
-	}
-	for _, extra := range pc.Middlewares.ExtraMiddlewares {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var middleware dynamic.TCPMiddleware
-		if err := json.Unmarshal(b, &middleware); err != nil {
-			continue
-		}
-		if middlewareName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			tcpConfig.Middlewares[middlewareName] = &middleware
+		if pc.Routers.Discover && pc.Routers.IncludeDependencies {
+			_, deps := overrides.TCPDependencies(tcpConfig.Routers, scope.Providers, nil, typedMiddlewares, providerCfg.PreservedProviders())
+			includeDependencies(&tcpConfig.Middlewares, typedMiddlewares, deps)
 		}
 	}
-	overrides.StripProvidersTCP(tcpConfig)
+	addExtras(&tcpConfig.Middlewares, pc.Middlewares.ExtraMiddlewares, "tcp.middlewares.extraMiddlewares")
+	generate(&tcpConfig.Middlewares, pc.Middlewares.Generators, tcpSources(raw), config.SourceMiddlewares, providerCfg)
+	overrides.StripProvidersTCP(tcpConfig, providerCfg.PreservedProviders()...)
+	report(overrides.RemoveTCPMiddlewares(tcpConfig, pc.Middlewares.Remove))
+	report(overrides.PatchTCPMiddlewares(tcpConfig.Middlewares, pc.Middlewares.Patches))
 }
 
 func ensureUDPDefaults(pc *config.UDPSection) {
@@ -243,59 +368,52 @@ func ensureUDPDefaults(pc *config.UDPSection) {
 	}
 }
 
-// ParseUDPConfig fills udpConfig from raw data according to providerConfig.
-func ParseUDPConfig(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, providerConfig *config.UDPSection, providerMatcher string) {
-	ensureUDPDefaults(providerConfig)
-	if providerConfig.Routers.Discover {
-		processUDPRouters(raw, udpConfig, providerConfig, providerMatcher)
+// ParseUDPConfig fills udpConfig from raw data according to the provider's UDP section and matcher.
+func ParseUDPConfig(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, providerCfg *config.ProviderConfig) {
+	pc := providerCfg.UDP
+	ensureUDPDefaults(pc)
+	var scope overrides.Scope
+	if pc.Routers.Discover {
+		scope = processUDPRouters(raw, udpConfig, providerCfg)
 	}
-	if providerConfig.Services.Discover {
-		processUDPServices(raw, udpConfig, providerConfig, providerMatcher)
+	if pc.Services.Discover {
+		processUDPServices(raw, udpConfig, providerCfg, scope)
 	}
 }
 
-func processUDPRouters(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, pc *config.UDPSection, providerMatcher string) {
+// processUDPRouters returns the scope recording the provider each router was discovered from.
+func processUDPRouters(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, providerCfg *config.ProviderConfig) overrides.Scope {
+	pc, providerMatcher := providerCfg.UDP, providerCfg.Matcher
 	if routers, ok := raw["udpRouters"]; ok {
 		typedRouters := convertToTyped[dynamic.UDPRouter](routers)
 		udpConfig.Routers = matchers.UDPRouters(typedRouters, pc.Routers, providerMatcher)
 	}
-	for _, extra := range pc.Routers.ExtraRoutes {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var router dynamic.UDPRouter
-		if err := json.Unmarshal(b, &router); err != nil {
-			continue
-		}
-		if routerName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			udpConfig.Routers[routerName] = &router
-		}
-	}
-	overrides.StripProvidersUDP(udpConfig)
-	overrides.OverrideUDPRouters(udpConfig.Routers, pc.Routers.Overrides)
+	addExtras(&udpConfig.Routers, pc.Routers.ExtraRoutes, "udp.routers.extraRoutes")
+	generate(&udpConfig.Routers, pc.Routers.Generators, udpSources(raw), config.SourceRouters, providerCfg)
+	scope := overrides.NewScope(providerCfg.Name, udpConfig.Routers)
+	overrides.StripProvidersUDP(udpConfig, providerCfg.PreservedProviders()...)
+	overrides.MapUDPEntrypoints(udpConfig.Routers, providerCfg.EntrypointMap)
+	report(overrides.OverrideUDPRouters(udpConfig.Routers, pc.Routers.Overrides, scope))
+	report(overrides.RemoveUDPRouters(udpConfig.Routers, pc.Routers.Remove))
+	report(overrides.PatchUDPRouters(udpConfig.Routers, pc.Routers.Patches))
+	return scope
 }
 
-func processUDPServices(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, pc *config.UDPSection, providerMatcher string) {
+func processUDPServices(raw map[string]interface{}, udpConfig *dynamic.UDPConfiguration, providerCfg *config.ProviderConfig, scope overrides.Scope) {
+	pc, providerMatcher := providerCfg.UDP, providerCfg.Matcher
 	if services, ok := raw["udpServices"]; ok {
 		typedServices := convertToTyped[dynamic.UDPService](services)
 		udpConfig.Services = matchers.UDPServices(typedServices, pc.Services, providerMatcher)
-	}
-	for _, extra := range pc.Services.ExtraServices {
-		b, err := json.Marshal(extra)
-		if err != nil {
-			continue
-		}
-		var service dynamic.UDPService
-		if err := json.Unmarshal(b, &service); err != nil {
-			continue
-		}
-		if serviceName, ok := extra.(map[string]interface{})["name"].(string); ok {
-			udpConfig.Services[serviceName] = &service
+		if pc.Routers.Discover && pc.Routers.IncludeDependencies {
+			includeDependencies(&udpConfig.Services, typedServices, overrides.UDPDependencies(udpConfig.Routers, scope.Providers, typedServices, providerCfg.PreservedProviders()))
 		}
 	}
-	overrides.StripProvidersUDP(udpConfig)
-	overrides.OverrideUDPServices(udpConfig.Services, pc.Services.Overrides)
+	addExtras(&udpConfig.Services, pc.Services.ExtraServices, "udp.services.extraServices")
+	generate(&udpConfig.Services, pc.Services.Generators, udpSources(raw), config.SourceServices, providerCfg)
+	overrides.StripProvidersUDP(udpConfig, providerCfg.PreservedProviders()...)
+	report(overrides.OverrideUDPServices(udpConfig.Services, pc.Services.Overrides))
+	report(overrides.RemoveUDPServices(udpConfig.Services, pc.Services.Remove))
+	report(overrides.PatchUDPServices(udpConfig.Services, pc.Services.Patches))
 }
 
 // ParseTLSConfig fills tlsConfig from raw data according to providerConfig.
@@ -315,6 +433,12 @@ func parseTLSConfig(raw map[string]interface{}, tlsConfig *dynamic.TLSConfigurat
 	ParseTLSConfig(raw, tlsConfig, providerConfig)
 }
 
+// ApplyNaming renames the resources of a parsed provider configuration
+// according to the provider's naming strategy.
+func ApplyNaming(cfg *dynamic.Configuration, providerCfg *config.ProviderConfig) {
+	report(overrides.RenameConfiguration(cfg, providerCfg.Naming, providerCfg.Name))
+}
+
 // parseDynamicConfiguration mirrors the behavior used by the httpclient to build a dynamic.Configuration
 // from raw JSON and a provider configuration. This is used by tests to validate nil-section handling.
 func parseDynamicConfiguration(body []byte, providerCfg *config.ProviderConfig) (*dynamic.Configuration, error) {
@@ -346,22 +470,25 @@ func parseDynamicConfiguration(body []byte, providerCfg *config.ProviderConfig)
 	}
 
 	if providerCfg.HTTP.Discover {
-		ParseHTTPConfig(raw, httpConfig, providerCfg.HTTP, providerCfg.Matcher, providerCfg.Tunnels)
+		ParseHTTPConfig(raw, httpConfig, providerCfg)
 	}
 	if providerCfg.TCP.Discover {
-		ParseTCPConfig(raw, tcpConfig, providerCfg.TCP, providerCfg.Matcher, providerCfg.Tunnels)
+		ParseTCPConfig(raw, tcpConfig, providerCfg)
 	}
 	if providerCfg.UDP.Discover {
-		ParseUDPConfig(raw, udpConfig, providerCfg.UDP, providerCfg.Matcher)
+		ParseUDPConfig(raw, udpConfig, providerCfg)
 	}
 	if providerCfg.TLS.Discover {
 		ParseTLSConfig(raw, tlsConfig, providerCfg.TLS)
 	}
 
-	return &dynamic.Configuration{
+	cfg := &dynamic.Configuration{
 		HTTP: httpConfig,
 		TCP:  tcpConfig,
 		UDP:  udpConfig,
 		TLS:  tlsConfig,
-	}, nil
+	}
+	ApplyNaming(cfg, providerCfg)
+
+	return cfg, nil
 }
@@ -1,6 +1,8 @@
 package parsers
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/traefik/genconf/dynamic"
@@ -39,7 +41,7 @@ func TestParseHTTPConfigMarshalErrors(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseHTTPConfig(raw, httpConfig, providerConfig, "", nil)
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig})
 
 	if len(httpConfig.Routers) != 1 {
 		t.Errorf("Expected 1 router, got %d", len(httpConfig.Routers))
@@ -86,7 +88,7 @@ func TestHTTPRouters_DiscoverPriorityReset(t *testing.T) {
 		Middlewares: &config.MiddlewaresConfig{Discover: false},
 	}
 
-	ParseHTTPConfig(raw, httpConfig, pc, "", nil)
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: pc})
 
 	if got := httpConfig.Routers["r1"].Priority; got != 0 {
 		t.Fatalf("expected router priority reset to 0, got %d", got)
@@ -112,7 +114,7 @@ func TestTCPRouters_DiscoverPriorityReset(t *testing.T) {
 		Middlewares: &config.MiddlewaresConfig{Discover: false},
 	}
 
-	ParseTCPConfig(raw, tcpConfig, pc, "", nil)
+	ParseTCPConfig(raw, tcpConfig, &config.ProviderConfig{TCP: pc})
 
 	if got := tcpConfig.Routers["r1"].Priority; got != 0 {
 		t.Fatalf("expected TCP router priority reset to 0, got %d", got)
@@ -378,7 +380,7 @@ func TestParseHTTPConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ParseHTTPConfig(tt.raw, tt.httpConfig, tt.providerConfig, "", tt.tunnels)
+			ParseHTTPConfig(tt.raw, tt.httpConfig, &config.ProviderConfig{HTTP: tt.providerConfig, Tunnels: tt.tunnels})
 
 			if tt.expectError {
 				return
@@ -583,7 +585,7 @@ func TestParseTCPConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ParseTCPConfig(tt.raw, tt.tcpConfig, tt.providerConfig, "", tt.tunnels)
+			ParseTCPConfig(tt.raw, tt.tcpConfig, &config.ProviderConfig{TCP: tt.providerConfig, Tunnels: tt.tunnels})
 
 			if tt.expectError {
 				return
@@ -718,7 +720,7 @@ func TestParseUDPConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ParseUDPConfig(tt.raw, tt.udpConfig, tt.providerConfig, "")
+			ParseUDPConfig(tt.raw, tt.udpConfig, &config.ProviderConfig{UDP: tt.providerConfig})
 
 			if tt.expectError {
 				return
@@ -867,7 +869,7 @@ func TestParseHTTPConfigJSONMarshalErrors(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseHTTPConfig(raw, httpConfig, providerConfig, "", nil)
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig})
 
 	// Valid entries should be added despite marshal errors
 	if len(httpConfig.Routers) != 1 {
@@ -919,7 +921,7 @@ func TestParseHTTPConfigJSONUnmarshalErrors(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseHTTPConfig(raw, httpConfig, providerConfig, "", nil)
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig})
 
 	// Items with unmarshal errors should not be added
 	if len(httpConfig.Routers) != 0 {
@@ -966,7 +968,7 @@ func TestParseTCPConfigJSONMarshalErrors(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseTCPConfig(raw, tcpConfig, providerConfig, "", nil)
+	ParseTCPConfig(raw, tcpConfig, &config.ProviderConfig{TCP: providerConfig})
 
 	if len(tcpConfig.Routers) != 0 {
 		t.Errorf("Expected 0 routers, got %d", len(tcpConfig.Routers))
@@ -998,7 +1000,7 @@ func TestParseUDPConfigJSONMarshalErrors(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseUDPConfig(raw, udpConfig, providerConfig, "")
+	ParseUDPConfig(raw, udpConfig, &config.ProviderConfig{UDP: providerConfig})
 
 	if len(udpConfig.Routers) != 0 {
 		t.Errorf("Expected 0 routers, got %d", len(udpConfig.Routers))
@@ -1085,7 +1087,7 @@ func TestParseHTTPConfigExtraItemsWithoutName(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseHTTPConfig(raw, httpConfig, providerConfig, "", nil)
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig})
 
 	// Items without name should not be added
 	if len(httpConfig.Routers) != 0 {
@@ -1144,7 +1146,7 @@ func TestParseTCPConfigExtraItemsWithoutName(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseTCPConfig(raw, tcpConfig, providerConfig, "", nil)
+	ParseTCPConfig(raw, tcpConfig, &config.ProviderConfig{TCP: providerConfig})
 
 	// Items without name should not be added
 	if len(tcpConfig.Routers) != 0 {
@@ -1190,7 +1192,7 @@ func TestParseUDPConfigExtraItemsWithoutName(t *testing.T) {
 	}
 
 	raw := map[string]interface{}{}
-	ParseUDPConfig(raw, udpConfig, providerConfig, "")
+	ParseUDPConfig(raw, udpConfig, &config.ProviderConfig{UDP: providerConfig})
 
 	// Items without name should not be added
 	if len(udpConfig.Routers) != 0 {
@@ -1233,7 +1235,7 @@ func TestParseConfigMarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseHTTPConfig(map[string]interface{}{}, httpConfig, httpProviderConfig, "", nil)
+	ParseHTTPConfig(map[string]interface{}{}, httpConfig, &config.ProviderConfig{HTTP: httpProviderConfig})
 
 	// All should be empty due to marshal errors
 	if len(httpConfig.Routers) != 0 {
@@ -1277,7 +1279,7 @@ func TestParseConfigMarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseTCPConfig(map[string]interface{}{}, tcpConfig, tcpProviderConfig, "", nil)
+	ParseTCPConfig(map[string]interface{}{}, tcpConfig, &config.ProviderConfig{TCP: tcpProviderConfig})
 
 	// All should be empty due to marshal errors
 	if len(tcpConfig.Routers) != 0 {
@@ -1313,7 +1315,7 @@ func TestParseConfigMarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseUDPConfig(map[string]interface{}{}, udpConfig, udpProviderConfig, "")
+	ParseUDPConfig(map[string]interface{}{}, udpConfig, &config.ProviderConfig{UDP: udpProviderConfig})
 
 	// All should be empty due to marshal errors
 	if len(udpConfig.Routers) != 0 {
@@ -1362,7 +1364,7 @@ func TestParseConfigUnmarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseHTTPConfig(map[string]interface{}{}, httpConfig, httpProviderConfig, "", nil)
+	ParseHTTPConfig(map[string]interface{}{}, httpConfig, &config.ProviderConfig{HTTP: httpProviderConfig})
 
 	// All should be empty due to unmarshal errors
 	if len(httpConfig.Routers) != 0 {
@@ -1412,7 +1414,7 @@ func TestParseConfigUnmarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseTCPConfig(map[string]interface{}{}, tcpConfig, tcpProviderConfig, "", nil)
+	ParseTCPConfig(map[string]interface{}{}, tcpConfig, &config.ProviderConfig{TCP: tcpProviderConfig})
 
 	// All should be empty due to unmarshal errors
 	if len(tcpConfig.Routers) != 0 {
@@ -1452,7 +1454,7 @@ func TestParseConfigUnmarshalErrors(t *testing.T) {
 		},
 	}
 
-	ParseUDPConfig(map[string]interface{}{}, udpConfig, udpProviderConfig, "")
+	ParseUDPConfig(map[string]interface{}{}, udpConfig, &config.ProviderConfig{UDP: udpProviderConfig})
 
 	// All should be empty due to unmarshal errors
 	if len(udpConfig.Routers) != 0 {
@@ -1462,3 +1464,417 @@ func TestParseConfigUnmarshalErrors(t *testing.T) {
 		t.Errorf("Expected 0 services due to unmarshal error, got %d", len(udpConfig.Services))
 	}
 }
+
+func TestParseHTTPConfig_AppliesPatches(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"api@file": map[string]interface{}{"rule": "Host(`api`)", "service": "api@file"},
+		},
+		"middlewares": map[string]interface{}{
+			"headers@file": map[string]interface{}{"headers": map[string]interface{}{"customRequestHeaders": map[string]interface{}{"X-A": "1"}}},
+		},
+	}
+	providerConfig := &config.HTTPSection{
+		Routers: &config.RoutersConfig{
+			Discover: true,
+			Patches:  []config.Patch{{Matcher: "Name(`api`)", Merge: map[string]interface{}{"priority": 5}}},
+		},
+		Middlewares: &config.MiddlewaresConfig{
+			Discover: true,
+			Patches:  []config.Patch{{JSON: []config.PatchOperation{{Op: "remove", Path: "/headers/customRequestHeaders/X-A"}}}},
+		},
+	}
+
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig})
+
+	if got := httpConfig.Routers["api"].Priority; got != 5 {
+		t.Errorf("expected patched priority 5, got %d", got)
+	}
+	if got := httpConfig.Middlewares["headers"].Headers.CustomRequestHeaders; len(got) != 0 {
+		t.Errorf("expected header to be removed, got %v", got)
+	}
+}
+
+func TestParseHTTPConfig_WrapsServicesAfterTunnels(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"api@file": map[string]interface{}{"rule": "Host(`api`)", "service": "api@file"},
+		},
+		"services": map[string]interface{}{
+			"api@file": map[string]interface{}{"loadBalancer": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"url": "http://a"}}}},
+		},
+	}
+	providerConfig := &config.HTTPSection{
+		Routers: &config.RoutersConfig{Discover: true},
+		Services: &config.ServicesConfig{
+			Discover: true,
+			Overrides: config.ServiceOverrides{Wraps: []config.OverrideWrap{{
+				Type: config.WrapMirroring, Matcher: "Name(`api`)", Mirrors: []config.MirrorService{{Name: "shadow", Percent: 5}},
+			}}},
+		},
+	}
+	tunnels := []config.TunnelConfig{{Matcher: "Name(`api`)", Addresses: []string{"http://tunnel"}}}
+
+	ParseHTTPConfig(raw, httpConfig, &config.ProviderConfig{HTTP: providerConfig, Tunnels: tunnels})
+
+	if got := httpConfig.Routers["api"].Service; got != "api" {
+		t.Errorf("expected router to keep service api, got %q", got)
+	}
+	if m := httpConfig.Services["api"].Mirroring; m == nil || m.Service != "api-origin" {
+		t.Fatalf("expected api to be wrapped, got %+v", httpConfig.Services["api"])
+	}
+	origin := httpConfig.Services["api-origin"]
+	if origin == nil || len(origin.LoadBalancer.Servers) != 1 || origin.LoadBalancer.Servers[0].URL != "http://tunnel" {
+		t.Errorf("expected tunnel servers on api-origin, got %+v", origin)
+	}
+}
+
+func TestParseHTTPConfig_MapsEntrypointsBeforeOverrides(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"api@docker":     map[string]interface{}{"rule": "Host(`api`)", "service": "api", "entryPoints": []interface{}{"https"}},
+			"metrics@docker": map[string]interface{}{"rule": "Path(`/metrics`)", "service": "m", "entryPoints": []interface{}{"metrics"}},
+		},
+	}
+	providerConfig := &config.ProviderConfig{
+		EntrypointMap: &config.EntrypointMapConfig{
+			Mappings: map[string]string{"https": "websecure"},
+			Unmapped: config.UnmappedDropRouter,
+		},
+		HTTP: &config.HTTPSection{
+			Routers: &config.RoutersConfig{
+				Discover: true,
+				Overrides: config.RouterOverrides{Middlewares: []config.OverrideMiddleware{{
+					Matcher: "Entrypoint(`websecure`)", Value: "secure-headers",
+				}}},
+			},
+		},
+	}
+
+	ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+	if _, ok := httpConfig.Routers["metrics"]; ok {
+		t.Error("expected router with unmapped entrypoint to be dropped")
+	}
+	api := httpConfig.Routers["api"]
+	if api == nil || !reflect.DeepEqual(api.EntryPoints, []string{"websecure"}) {
+		t.Fatalf("unexpected api router: %+v", api)
+	}
+	if !reflect.DeepEqual(api.Middlewares, []string{"secure-headers"}) {
+		t.Errorf("expected override to match mapped entrypoint, got %v", api.Middlewares)
+	}
+}
+
+func TestParseHTTPConfig_GeneratedRoutersGoThroughPipeline(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	raw := map[string]interface{}{
+		"services": map[string]interface{}{
+			"api@docker": map[string]interface{}{"loadBalancer": map[string]interface{}{}},
+		},
+	}
+	providerConfig := &config.ProviderConfig{
+		Name: "edge",
+		HTTP: &config.HTTPSection{
+			Routers: &config.RoutersConfig{
+				Discover: true,
+				Generators: []config.Generator{{
+					Source: config.SourceServices,
+					Name:   "{{.Name}}-internal",
+					Template: map[string]interface{}{
+						"rule":    "Host(`{{.Name}}.svc.local`)",
+						"service": "{{.Name}}@docker",
+					},
+				}},
+				Overrides: config.RouterOverrides{Entrypoints: []config.OverrideEntrypoint{{
+					Matcher: "NameRegexp(`-internal$`)", Value: []string{"internal"},
+				}}},
+			},
+		},
+	}
+
+	ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+	r := httpConfig.Routers["api-internal"]
+	if r == nil {
+		t.Fatalf("expected generated router, got %v", httpConfig.Routers)
+	}
+	if r.Service != "api" {
+		t.Errorf("expected service reference to be stripped, got %q", r.Service)
+	}
+	if !reflect.DeepEqual(r.EntryPoints, []string{"internal"}) {
+		t.Errorf("expected override to apply to generated router, got %v", r.EntryPoints)
+	}
+}
+
+func TestParseHTTPConfig_HTTPSRedirect(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"api@file": map[string]interface{}{"rule": "Host(`api`)", "service": "api@file", "entryPoints": []interface{}{"websecure"}, "tls": map[string]interface{}{}},
+		},
+		"middlewares": map[string]interface{}{
+			"auth@file": map[string]interface{}{"basicAuth": map[string]interface{}{}},
+		},
+	}
+	providerConfig := &config.ProviderConfig{HTTP: &config.HTTPSection{
+		Routers: &config.RoutersConfig{Discover: true, HTTPSRedirect: &config.HTTPSRedirectConfig{}},
+	}}
+
+	ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+	r := httpConfig.Routers["api-redirect"]
+	if r == nil || r.Rule != "Host(`api`)" || !reflect.DeepEqual(r.Middlewares, []string{"redirect-to-https"}) {
+		t.Fatalf("unexpected redirect router: %+v", r)
+	}
+	if httpConfig.Middlewares["redirect-to-https"] == nil || httpConfig.Middlewares["auth"] == nil {
+		t.Errorf("expected discovered and redirect middlewares, got %v", httpConfig.Middlewares)
+	}
+}
+
+func TestParseHTTPConfig_ExtrasMapForm(t *testing.T) {
+	httpConfig := &dynamic.HTTPConfiguration{}
+	providerConfig := &config.ProviderConfig{HTTP: &config.HTTPSection{
+		Routers: &config.RoutersConfig{
+			Discover: true,
+			ExtraRoutes: map[string]interface{}{
+				"static": map[string]interface{}{"rule": "Host(`static`)", "service": "static@file"},
+			},
+		},
+	}}
+
+	ParseHTTPConfig(map[string]interface{}{}, httpConfig, providerConfig)
+
+	r := httpConfig.Routers["static"]
+	if r == nil || r.Rule != "Host(`static`)" || r.Service != "static" {
+		t.Fatalf("unexpected router: %+v", r)
+	}
+}
+
+func TestParseDynamicConfiguration_Naming(t *testing.T) {
+	providerConfig := &config.ProviderConfig{
+		Name:   "east",
+		Naming: &config.NamingConfig{Strategy: config.NamingPrefix, Value: "east-"},
+		HTTP: &config.HTTPSection{
+			Discover: true,
+			Routers:  &config.RoutersConfig{Discover: true, StripServiceProvider: true},
+			Services: &config.ServicesConfig{Discover: true},
+		},
+	}
+
+	jsonData := `{
+		"routers": {"api@file": {"rule": "Host(` + "`api`" + `)", "service": "api@file"}},
+		"services": {"api@file": {"loadBalancer": {"servers": [{"url": "http://api:80"}]}}}
+	}`
+	cfg, err := parseDynamicConfiguration([]byte(jsonData), providerConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := cfg.HTTP.Routers["east-api"]
+	if r == nil || r.Service != "east-api" {
+		t.Fatalf("unexpected routers: %+v", cfg.HTTP.Routers)
+	}
+	if _, ok := cfg.HTTP.Services["east-api"]; !ok {
+		t.Errorf("unexpected services: %+v", cfg.HTTP.Services)
+	}
+}
+
+func TestParseHTTPConfig_PreservesInternalReferences(t *testing.T) {
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"dashboard@docker": map[string]interface{}{
+				"rule":        "Host(`traefik.local`)",
+				"service":     "api@internal",
+				"middlewares": []interface{}{"auth@file", "headers@docker"},
+			},
+		},
+	}
+	httpConfig := &dynamic.HTTPConfiguration{}
+	providerConfig := &config.ProviderConfig{
+		PreserveProviders: []string{"internal", "file"},
+		HTTP:              &config.HTTPSection{Discover: true, Routers: &config.RoutersConfig{Discover: true}},
+	}
+	ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+	r := httpConfig.Routers["dashboard"]
+	if r == nil {
+		t.Fatalf("unexpected routers: %+v", httpConfig.Routers)
+	}
+	if r.Service != "api@internal" || !reflect.DeepEqual(r.Middlewares, []string{"auth@file", "headers"}) {
+		t.Errorf("unexpected router: %+v", r)
+	}
+}
+
+func TestParseHTTPConfig_IncludeDependencies(t *testing.T) {
+	raw := map[string]interface{}{
+		"routers": map[string]interface{}{
+			"api@docker":   map[string]interface{}{"rule": "Host(`api`)", "service": "api", "middlewares": []interface{}{"secured"}},
+			"other@docker": map[string]interface{}{"rule": "Host(`other`)", "service": "other"},
+		},
+		"services": map[string]interface{}{
+			"api@docker":    map[string]interface{}{"weighted": map[string]interface{}{"services": []interface{}{map[string]interface{}{"name": "api-v1"}}}},
+			"api-v1@docker": map[string]interface{}{"loadBalancer": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"url": "http://api:80"}}}},
+			"other@docker":  map[string]interface{}{"loadBalancer": map[string]interface{}{}},
+		},
+		"middlewares": map[string]interface{}{
+			"secured@docker": map[string]interface{}{"chain": map[string]interface{}{"middlewares": []interface{}{"auth"}}},
+			"auth@docker":    map[string]interface{}{"basicAuth": map[string]interface{}{"users": []interface{}{"u:p"}}},
+			"other@docker":   map[string]interface{}{"headers": map[string]interface{}{}},
+		},
+	}
+	httpConfig := &dynamic.HTTPConfiguration{}
+	providerConfig := &config.ProviderConfig{
+		HTTP: &config.HTTPSection{
+			Discover:    true,
+			Routers:     &config.RoutersConfig{Discover: true, Matcher: "Name(`api@docker`)", IncludeDependencies: true},
+			Services:    &config.ServicesConfig{Discover: true, Matcher: "Name(`none`)"},
+			Middlewares: &config.MiddlewaresConfig{Discover: true, Matcher: "Name(`none`)"},
+		},
+	}
+	ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+	if got := sortedNames(httpConfig.Services); !reflect.DeepEqual(got, []string{"api", "api-v1"}) {
+		t.Errorf("services=%v", got)
+	}
+	if got := sortedNames(httpConfig.Middlewares); !reflect.DeepEqual(got, []string{"auth", "secured"}) {
+		t.Errorf("middlewares=%v", got)
+	}
+}
+
+func TestParseHTTPConfig_IncludeDependenciesSameBareName(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		raw := map[string]interface{}{
+			"routers": map[string]interface{}{
+				"api@docker": map[string]interface{}{"rule": "Host(`api`)", "service": "api", "middlewares": []interface{}{"auth"}},
+			},
+			"services": map[string]interface{}{
+				"api@docker": map[string]interface{}{"loadBalancer": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"url": "http://docker:80"}}}},
+				"api@file":   map[string]interface{}{"loadBalancer": map[string]interface{}{"servers": []interface{}{map[string]interface{}{"url": "http://file:80"}}}},
+			},
+			"middlewares": map[string]interface{}{
+				"auth@docker": map[string]interface{}{"basicAuth": map[string]interface{}{"users": []interface{}{"docker:p"}}},
+				"auth@file":   map[string]interface{}{"basicAuth": map[string]interface{}{"users": []interface{}{"file:p"}}},
+			},
+		}
+		httpConfig := &dynamic.HTTPConfiguration{}
+		providerConfig := &config.ProviderConfig{
+			HTTP: &config.HTTPSection{
+				Discover:    true,
+				Routers:     &config.RoutersConfig{Discover: true, IncludeDependencies: true},
+				Services:    &config.ServicesConfig{Discover: true, Matcher: "Name(`none`)"},
+				Middlewares: &config.MiddlewaresConfig{Discover: true, Matcher: "Name(`none`)"},
+			},
+		}
+		ParseHTTPConfig(raw, httpConfig, providerConfig)
+
+		if got := httpConfig.Services["api"].LoadBalancer.Servers[0].URL; got != "http://docker:80" {
+			t.Fatalf("expected the router's provider service, got %q", got)
+		}
+		if got := httpConfig.Middlewares["auth"].BasicAuth.Users[0]; got != "docker:p" {
+			t.Fatalf("expected the router's provider middleware, got %q", got)
+		}
+	}
+}
+
+func sortedNames[T any](m map[string]*T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestParseDynamicConfiguration_Models(t *testing.T) {
+	providerConfig := &config.ProviderConfig{
+		Name:   "east",
+		Naming: &config.NamingConfig{Strategy: config.NamingPrefix, Value: "east-"},
+		HTTP: &config.HTTPSection{
+			Discover:    true,
+			Middlewares: &config.MiddlewaresConfig{Discover: true},
+			Models: &config.ModelsConfig{
+				Discover: true,
+				Matcher:  "Entrypoint(`websecure`)",
+				ExtraModels: map[string]interface{}{
+					"internal": map[string]interface{}{"middlewares": []interface{}{"auth@internal"}},
+				},
+			},
+		},
+	}
+
+	jsonData := `{
+		"middlewares": {"headers@file": {"headers": {"frameDeny": true}}},
+		"models": {
+			"websecure@file": {"middlewares": ["headers@file"], "tls": {"certResolver": "le"}},
+			"web@file": {"middlewares": ["headers@file"]}
+		}
+	}`
+	cfg, err := parseDynamicConfiguration([]byte(jsonData), providerConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	models := cfg.HTTP.Models
+	if len(models) != 2 || models["web"] != nil {
+		t.Fatalf("unexpected models: %v", models)
+	}
+	m := models["websecure"]
+	if m == nil || m.TLS == nil || m.TLS.CertResolver != "le" {
+		t.Fatalf("expected websecure model to keep its name and TLS: %+v", m)
+	}
+	if !reflect.DeepEqual(m.Middlewares, []string{"east-headers"}) {
+		t.Errorf("middlewares=%v", m.Middlewares)
+	}
+	if got := models["internal"].Middlewares; !reflect.DeepEqual(got, []string{"auth@internal"}) {
+		t.Errorf("extra model middlewares=%v", got)
+	}
+}
+
+func TestParseDynamicConfiguration_ServersTransports(t *testing.T) {
+	providerConfig := &config.ProviderConfig{
+		Name: "east",
+		HTTP: &config.HTTPSection{
+			Discover: true,
+			Services: &config.ServicesConfig{Discover: true},
+			ServersTransports: &config.ServersTransportsConfig{
+				Discover:  true,
+				Overrides: []config.OverrideServersTransport{{Matcher: "Name(`mytransport`)", ServerName: "api.internal"}},
+				ExtraServersTransports: []interface{}{
+					map[string]interface{}{"name": "local", "insecureSkipVerify": true},
+				},
+			},
+		},
+	}
+
+	jsonData := `{
+		"services": {"api@file": {"loadBalancer": {"servers": [{"url": "https://api:443"}], "serversTransport": "mytransport@file"}}},
+		"serversTransports": {"mytransport@file": {"serverName": "upstream", "maxIdleConnsPerHost": 4}}
+	}`
+	cfg, err := parseDynamicConfiguration([]byte(jsonData), providerConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st := cfg.HTTP.ServersTransports["mytransport"]
+	if st == nil || st.ServerName != "api.internal" || st.MaxIdleConnsPerHost != 4 {
+		t.Fatalf("unexpected transports: %+v", cfg.HTTP.ServersTransports)
+	}
+	if !cfg.HTTP.ServersTransports["local"].InsecureSkipVerify {
+		t.Error("expected the extra transport")
+	}
+	if got := cfg.HTTP.Services["api"].LoadBalancer.ServersTransport; got != "mytransport" {
+		t.Errorf("serversTransport reference=%q", got)
+	}
+}
+
+func TestAddExtras_CopiesDecodedExtras(t *testing.T) {
+	extras := map[string]*dynamic.Router{"dashboard": {Rule: "Host(`dash`)", EntryPoints: []string{"web"}}}
+
+	var routers map[string]*dynamic.Router
+	addExtras(&routers, extras, "http.routers.extraRoutes")
+	routers["dashboard"].EntryPoints[0] = "websecure"
+
+	if routers["dashboard"] == extras["dashboard"] || extras["dashboard"].EntryPoints[0] != "web" {
+		t.Fatalf("extras were not copied: %+v", extras["dashboard"])
+	}
+}
@@ -0,0 +1,10 @@
+package internal
+
+import "github.com/zalbiraw/traefikprovider/internal/overrides"
+
+// CheckReferences validates the router references of a merged configuration.
+// Each router follows the dangling reference policy of the provider it was
+// kept from.
+func CheckReferences(result *MergeResult) []error {
+	return overrides.CheckReferences(result.Config, result.Origin)
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+)
+
+func TestCheckReferences_UsesOriginPolicy(t *testing.T) {
+	c1 := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"a":      {Service: "missing"},
+			"shared": {Service: "missing"},
+		},
+	}}
+	c2 := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers: map[string]*dynamic.Router{
+			"b":      {Service: "missing"},
+			"shared": {Service: "svc"},
+		},
+		Services: map[string]*dynamic.Service{"svc": {}},
+	}}
+	providers := []config.ProviderConfig{
+		{Name: "one", DanglingReferences: config.DanglingDropRouter},
+		{Name: "two"},
+	}
+	result := Merge([]Source{{Provider: &providers[0], Config: c1}, {Provider: &providers[1], Config: c2}}, nil)
+	merged := result.Config
+
+	errs := CheckReferences(result)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+	if _, ok := merged.HTTP.Routers["a"]; ok {
+		t.Error("router a should be dropped by provider one's policy")
+	}
+	if _, ok := merged.HTTP.Routers["b"]; !ok {
+		t.Error("router b should be kept by provider two's policy")
+	}
+	if _, ok := merged.HTTP.Routers["shared"]; !ok {
+		t.Error("router shared was merged from provider two and is valid")
+	}
+}
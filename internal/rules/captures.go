@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Captures returns the submatches of the first regexp matcher in the program
+// that matches ctx, with the whole match at index 0. Matchers below a negation
+// never contribute captures. It returns nil when no regexp matcher matches.
+func (p *Program) Captures(ctx Context) []string {
+	if p == nil || p.expr == nil {
+		return nil
+	}
+	return captures(p.expr, ctx)
+}
+
+func captures(e Expr, ctx Context) []string {
+	switch n := e.(type) {
+	case BinaryExpr:
+		if c := captures(n.Left, ctx); c != nil {
+			return c
+		}
+		return captures(n.Right, ctx)
+	case CallExpr:
+		return callCaptures(n.Name, n.Arg, ctx)
+	default:
+		return nil
+	}
+}
+
+func callCaptures(name, arg string, ctx Context) []string {
+	switch strings.ToLower(name) {
+	case "nameregexp":
+		return submatch(arg, ctx.Name)
+	case "providerregexp":
+		return submatch(arg, ctx.Provider)
+	case "serviceregexp":
+		return submatch(arg, ctx.Service)
+	case "entrypointregexp":
+		for _, e := range ctx.Entrypoints {
+			if c := submatch(arg, e); c != nil {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func submatch(pattern, value string) []string {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re.FindStringSubmatch(value)
+}
@@ -0,0 +1,31 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProgram_Captures(t *testing.T) {
+	cases := []struct {
+		rule string
+		ctx  Context
+		want []string
+	}{
+		{"NameRegexp(`^api-(.*)$`)", Context{Name: "api-users"}, []string{"api-users", "users"}},
+		{"Name(`x`) || ServiceRegexp(`(.+)-svc`)", Context{Name: "y", Service: "orders-svc"}, []string{"orders-svc", "orders"}},
+		{"EntrypointRegexp(`^web(.*)`)", Context{Entrypoints: []string{"tcp", "websecure"}}, []string{"websecure", "secure"}},
+		{"NameRegexp(`^a(.)`) && NameRegexp(`(b)$`)", Context{Name: "axb"}, []string{"ax", "x"}},
+		{"!NameRegexp(`(z)`)", Context{Name: "z"}, nil},
+		{"Name(`api`)", Context{Name: "api"}, nil},
+		{"", Context{Name: "api"}, nil},
+	}
+	for _, c := range cases {
+		prog, err := Compile(c.rule)
+		if err != nil {
+			t.Fatalf("compile %q: %v", c.rule, err)
+		}
+		if got := prog.Captures(c.ctx); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Captures(%q)=%v want %v", c.rule, got, c.want)
+		}
+	}
+}
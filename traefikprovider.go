@@ -12,6 +12,7 @@ import (
 	"github.com/zalbiraw/traefikprovider/config"
 	"github.com/zalbiraw/traefikprovider/internal"
 	"github.com/zalbiraw/traefikprovider/internal/httpclient"
+	"github.com/zalbiraw/traefikprovider/internal/overrides"
 )
 
 // CreateConfig creates the default plugin configuration.
@@ -39,6 +40,16 @@ func CreateConfig() *Config {
 type Config struct {
 	PollInterval string                  `json:"pollInterval,omitempty" yaml:"pollInterval,omitempty"`
 	Providers    []config.ProviderConfig `json:"providers,omitempty" yaml:"providers,omitempty"`
+	// EnforceMiddlewares is applied to the routers of the merged configuration.
+	EnforceMiddlewares *config.EnforceMiddlewaresConfig `json:"enforceMiddlewares,omitempty" yaml:"enforceMiddlewares,omitempty"`
+	// Prune drops merged services, middlewares and servers transports that no router uses.
+	Prune *config.PruneConfig `json:"prune,omitempty" yaml:"prune,omitempty"`
+	// Merge sets how resources defined by several providers are merged.
+	Merge *config.MergeConfig `json:"merge,omitempty" yaml:"merge,omitempty"`
+	// Models are HTTP models added to the merged configuration, replacing
+	// upstream models with the same name. Like extras, a list of objects with
+	// a "name" field or a map of name to object.
+	Models interface{} `json:"models,omitempty" yaml:"models,omitempty"`
 }
 
 // Provider implements the Traefik provider plugin lifecycle.
@@ -46,6 +57,10 @@ type Provider struct {
 	name         string
 	pollInterval time.Duration
 	config       *Config
+	models       map[string]*dynamic.Model
+	// missingFiles holds, per provider config, the servers transport files
+	// already reported missing, so that they are not logged on every poll.
+	missingFiles []map[string]bool
 	cancel       func()
 }
 
@@ -65,7 +80,8 @@ func New(ctx context.Context, config *Config, name string) (*Provider, error) {
 	if len(config.Providers) == 0 {
 		return nil, fmt.Errorf("at least one ProviderConfig is required")
 	}
-	for i, p := range config.Providers {
+	for i := range config.Providers {
+		p := &config.Providers[i]
 		if p.Name == "" {
 			return nil, fmt.Errorf("provider[%d]: Name is required", i)
 		}
@@ -75,15 +91,57 @@ func New(ctx context.Context, config *Config, name string) (*Provider, error) {
 		if p.Connection.Port == 0 {
 			return nil, fmt.Errorf("provider[%d]: Connection.Port is required", i)
 		}
+		if err := p.Normalize(); err != nil {
+			return nil, fmt.Errorf("provider[%d]: %w", i, err)
+		}
+	}
+
+	if err := config.EnforceMiddlewares.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Merge.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Prune.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Merge != nil {
+		names := make(map[string]bool, len(config.Providers))
+		for i := range config.Providers {
+			names[config.Providers[i].Name] = true
+		}
+		for i, f := range config.Merge.Failovers {
+			for _, name := range f.Providers {
+				if !names[name] {
+					return nil, fmt.Errorf("merge.failovers[%d].providers: unknown provider %q", i, name)
+				}
+			}
+		}
+	}
+
+	models, err := decodeModels(config.Models)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Provider{
 		name:         name,
 		pollInterval: pi,
 		config:       config,
+		models:       models,
+		missingFiles: make([]map[string]bool, len(config.Providers)),
 	}, nil
 }
 
+// decodeModels decodes the root-level models.
+func decodeModels(models interface{}) (map[string]*dynamic.Model, error) {
+	decoded, errs := config.DecodeExtras[dynamic.Model](models)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("models: %w", errs[0])
+	}
+	return decoded, nil
+}
+
 // Init validates the provider configuration before starting.
 func (p *Provider) Init() error {
 	if p.pollInterval <= 0 {
@@ -118,12 +176,25 @@ func (p *Provider) loadConfiguration(ctx context.Context, cfgChan chan<- json.Ma
 	for {
 		select {
 		case <-ticker.C:
-			var configs []*dynamic.Configuration
+			sources := make([]internal.Source, 0, len(p.config.Providers))
 			for i := range p.config.Providers {
-				cfg := httpclient.GenerateConfiguration(&p.config.Providers[i])
-				configs = append(configs, cfg)
+				pc := &p.config.Providers[i]
+				cfg := httpclient.GenerateConfiguration(pc)
+				p.checkFiles(i, cfg)
+				sources = append(sources, internal.Source{Provider: pc, Config: cfg})
 			}
-			merged := internal.MergeConfigurations(configs...)
+			result := internal.Merge(sources, p.config.Merge)
+			for _, c := range result.Conflicts {
+				log.Printf("traefikprovider: merge conflict: %v", c)
+			}
+			for _, w := range result.Warnings {
+				log.Printf("traefikprovider: merge warning: %v", w)
+			}
+			if result.Failed() {
+				log.Printf("traefikprovider: merge conflicts with policy %q, configuration not updated", config.MergeError)
+				continue
+			}
+			merged := p.finalize(result)
 			cfgChan <- &dynamic.JSONPayload{Configuration: merged}
 		case <-ctx.Done():
 			return
@@ -131,6 +202,41 @@ func (p *Provider) loadConfiguration(ctx context.Context, cfgChan chan<- json.Ma
 	}
 }
 
+// checkFiles logs the servers transport files of provider config i that are
+// missing on this host, once until they reappear.
+func (p *Provider) checkFiles(i int, cfg *dynamic.Configuration) {
+	if cfg.HTTP == nil {
+		return
+	}
+	if p.missingFiles[i] == nil {
+		p.missingFiles[i] = map[string]bool{}
+	}
+	for _, err := range overrides.CheckServersTransportFiles(cfg.HTTP.ServersTransports, p.missingFiles[i]) {
+		log.Printf("traefikprovider: provider %q: %v", p.config.Providers[i].Name, err)
+	}
+}
+
+// finalize applies the root-level settings to a merged configuration: root
+// models and enforced middlewares are added, then dangling references are
+// checked and unused resources pruned.
+func (p *Provider) finalize(result *internal.MergeResult) *dynamic.Configuration {
+	merged := result.Config
+	for name, model := range p.models {
+		merged.HTTP.Models[name] = model
+	}
+	overrides.EnforceMiddlewares(merged, p.config.EnforceMiddlewares)
+	if errs := internal.CheckReferences(result); len(errs) > 0 {
+		log.Printf("traefikprovider: merged configuration has dangling references:")
+		for _, err := range errs {
+			log.Printf("traefikprovider:   %v", err)
+		}
+	}
+	for _, err := range overrides.Prune(merged, p.config.Prune) {
+		log.Printf("traefikprovider: %v", err)
+	}
+	return merged
+}
+
 // Stop stops the background polling goroutine.
 func (p *Provider) Stop() error {
 	p.cancel()
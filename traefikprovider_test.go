@@ -0,0 +1,128 @@
+package traefikprovider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/traefik/genconf/dynamic"
+	"github.com/zalbiraw/traefikprovider/config"
+	"github.com/zalbiraw/traefikprovider/internal"
+)
+
+func testConfig() *Config {
+	cfg := CreateConfig()
+	cfg.Providers = []config.ProviderConfig{
+		{Name: "east", Connection: config.ConnectionConfig{Host: "east", Port: 8080}},
+		{Name: "west", Connection: config.ConnectionConfig{Host: "west", Port: 8080}},
+	}
+	return cfg
+}
+
+func TestNew_Models(t *testing.T) {
+	cfg := testConfig()
+	cfg.Models = map[string]interface{}{
+		"websecure": map[string]interface{}{"middlewares": []interface{}{"headers"}},
+	}
+	p, err := New(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := p.models["websecure"]; m == nil || !reflect.DeepEqual(m.Middlewares, []string{"headers"}) {
+		t.Errorf("unexpected models: %v", p.models)
+	}
+
+	cfg.Models = []interface{}{map[string]interface{}{"middlewares": []interface{}{"headers"}}}
+	if _, err := New(context.Background(), cfg, "test"); err == nil || !strings.Contains(err.Error(), "models: [0]: missing name") {
+		t.Errorf("expected a models error, got %v", err)
+	}
+}
+
+func TestNew_FailoverUnknownProvider(t *testing.T) {
+	cfg := testConfig()
+	cfg.Merge = &config.MergeConfig{Failovers: []config.FailoverService{{Service: "api", Providers: []string{"east", "north"}}}}
+	_, err := New(context.Background(), cfg, "test")
+	if err == nil || !strings.Contains(err.Error(), `merge.failovers[0].providers: unknown provider "north"`) {
+		t.Errorf("expected an unknown provider error, got %v", err)
+	}
+
+	cfg.Merge.Failovers[0].Providers = []string{"east", "west"}
+	if _, err := New(context.Background(), cfg, "test"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNew_PruneKeepMatcher(t *testing.T) {
+	cfg := testConfig()
+	cfg.Prune = &config.PruneConfig{KeepMiddlewares: "Name(`auth`"}
+	_, err := New(context.Background(), cfg, "test")
+	if err == nil || !strings.Contains(err.Error(), "prune.keepMiddlewares") {
+		t.Errorf("expected a keep matcher error, got %v", err)
+	}
+}
+
+func TestFinalize_Models(t *testing.T) {
+	cfg := testConfig()
+	cfg.Models = map[string]interface{}{
+		"websecure": map[string]interface{}{"middlewares": []interface{}{"headers"}},
+	}
+	cfg.Prune = &config.PruneConfig{}
+	p, err := New(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := internal.Merge([]internal.Source{{Provider: &cfg.Providers[0], Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Middlewares: map[string]*dynamic.Middleware{"headers": {}, "unused": {}},
+		Models:      map[string]*dynamic.Model{"websecure": {Middlewares: []string{"unused"}}, "web": {}},
+	}}}}, nil)
+	merged := p.finalize(result)
+
+	models := merged.HTTP.Models
+	if len(models) != 2 || !reflect.DeepEqual(models["websecure"].Middlewares, []string{"headers"}) {
+		t.Errorf("expected the root model to replace the merged one: %+v", models)
+	}
+	if _, ok := merged.HTTP.Middlewares["headers"]; !ok {
+		t.Error("expected the middleware used by the root model to survive pruning")
+	}
+	if _, ok := merged.HTTP.Middlewares["unused"]; ok {
+		t.Error("expected the middleware of the replaced model to be pruned")
+	}
+}
+
+func TestFinalize_CheckReferencesAfterRootAdditions(t *testing.T) {
+	cfg := testConfig()
+	cfg.Providers[0].DanglingReferences = config.DanglingDropMiddleware
+	cfg.EnforceMiddlewares = &config.EnforceMiddlewaresConfig{HTTP: []config.EnforceMiddleware{{Name: "auth"}}}
+	p, err := New(context.Background(), cfg, "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := internal.Merge([]internal.Source{{Provider: &cfg.Providers[0], Config: &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers:  map[string]*dynamic.Router{"api": {Service: "api"}},
+		Services: map[string]*dynamic.Service{"api": {}},
+	}}}}, nil)
+	merged := p.finalize(result)
+
+	if got := merged.HTTP.Routers["api"].Middlewares; len(got) != 0 {
+		t.Errorf("expected the missing enforced middleware to be dropped, got %v", got)
+	}
+}
+
+func TestProvider_CheckFilesPerProvider(t *testing.T) {
+	p, err := New(context.Background(), testConfig(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		ServersTransports: map[string]*dynamic.ServersTransport{"mtls": {RootCAs: []string{"/missing/ca.pem"}}},
+	}}
+	p.checkFiles(0, cfg)
+	p.checkFiles(1, &dynamic.Configuration{})
+
+	if len(p.missingFiles[0]) != 1 || len(p.missingFiles[1]) != 0 {
+		t.Errorf("unexpected missing files: %v", p.missingFiles)
+	}
+}